@@ -93,3 +93,27 @@ func MakeCopy(bytes []byte) []byte {
 	copy(copied, bytes)
 	return copied
 }
+
+// FixedEncoderDecoder encodes fixed-size values (and slices of them) using Write and
+// Read.
+type FixedEncoderDecoder struct{}
+
+func (e *FixedEncoderDecoder) Encode(value interface{}) ([]byte, error) { return Marshal(value) }
+
+func (e *FixedEncoderDecoder) EncodeStatic(value interface{}) []byte {
+	b, err := e.Encode(value)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (e *FixedEncoderDecoder) Decode(data []byte, value interface{}) error {
+	return Read(bytes.NewReader(data), value)
+}
+
+func (e *FixedEncoderDecoder) DecodeStatic(data []byte, value interface{}) {
+	if err := e.Decode(data, value); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,49 @@
+package compress
+
+import (
+	"github.com/arya-analytics/x/binary"
+)
+
+// EncoderDecoder wraps a binary.EncoderDecoder so that all encoded values are
+// compressed, and all decoded values are decompressed before being decoded. To
+// create a new EncoderDecoder, call WrapEncoderDecoder.
+type EncoderDecoder struct {
+	binary.EncoderDecoder
+	*Compressor
+}
+
+// WrapEncoderDecoder wraps the provided binary.EncoderDecoder with a Compressor
+// configured using the provided options.
+func WrapEncoderDecoder(ecd binary.EncoderDecoder, opts ...Option) *EncoderDecoder {
+	return &EncoderDecoder{EncoderDecoder: ecd, Compressor: New(opts...)}
+}
+
+// Encode implements binary.Encoder.
+func (e *EncoderDecoder) Encode(value interface{}) ([]byte, error) {
+	b, err := e.EncoderDecoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return e.Compress(b), nil
+}
+
+// EncodeStatic implements binary.Encoder.
+func (e *EncoderDecoder) EncodeStatic(value interface{}) []byte {
+	b, err := e.Encode(value)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Decode implements binary.Decoder.
+func (e *EncoderDecoder) Decode(data []byte, value interface{}) error {
+	return e.EncoderDecoder.Decode(e.Decompress(data), value)
+}
+
+// DecodeStatic implements binary.Decoder.
+func (e *EncoderDecoder) DecodeStatic(data []byte, value interface{}) {
+	if err := e.Decode(data, value); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,36 @@
+package compress_test
+
+import (
+	"github.com/arya-analytics/x/binary"
+	"github.com/arya-analytics/x/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+type value struct {
+	Key  int
+	Data string
+}
+
+var _ = Describe("EncoderDecoder", func() {
+	var (
+		gob  = &binary.GobEncoderDecoder{}
+		ecdc = compress.WrapEncoderDecoder(gob, compress.WithCodec(compress.Zstd))
+		v    = value{Key: 1, Data: strings.Repeat("data", 200)}
+	)
+	It("Should compress encoded values", func() {
+		raw := gob.EncodeStatic(v)
+		b, err := ecdc.Encode(v)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(b)).To(BeNumerically("<", len(raw)))
+		var res value
+		Expect(ecdc.Decode(b, &res)).To(Succeed())
+		Expect(res).To(Equal(v))
+	})
+	It("Should decode values encoded without compression", func() {
+		var res value
+		Expect(ecdc.Decode(gob.EncodeStatic(v), &res)).To(Succeed())
+		Expect(res).To(Equal(v))
+	})
+})
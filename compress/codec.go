@@ -0,0 +1,89 @@
+package compress
+
+import (
+	"bytes"
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+)
+
+// Codec is an algorithm used to compress and decompress values. The available
+// codecs are Snappy and Zstd.
+type Codec interface {
+	// String returns the name of the codec.
+	String() string
+	header() byte
+	compress(dst, src []byte) []byte
+	decompress(src []byte) ([]byte, error)
+}
+
+var (
+	// Snappy is a fast codec with a moderate compression ratio. It's the default
+	// Codec used by a Compressor.
+	Snappy Codec = snappyCodec{}
+	// Zstd is a codec with a high compression ratio, at the cost of higher CPU usage
+	// than Snappy.
+	Zstd Codec = &zstdCodec{}
+)
+
+// |||||| SNAPPY ||||||
+
+type snappyCodec struct{}
+
+// String implements Codec.
+func (snappyCodec) String() string { return "snappy" }
+
+func (snappyCodec) header() byte { return headerSnappy }
+
+func (snappyCodec) compress(dst, src []byte) []byte {
+	buf := make([]byte, snappy.MaxEncodedLen(len(src)))
+	return append(dst, snappy.Encode(buf, src)...)
+}
+
+func (snappyCodec) decompress(src []byte) ([]byte, error) { return snappy.Decode(nil, src) }
+
+// |||||| ZSTD ||||||
+
+// zstdMagic is the magic number that starts every zstd frame.
+var zstdMagic = []byte{0x28, 0xB5, 0x2F, 0xFD}
+
+type zstdCodec struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// String implements Codec.
+func (z *zstdCodec) String() string { return "zstd" }
+
+func (z *zstdCodec) header() byte { return headerZstd }
+
+func (z *zstdCodec) compress(dst, src []byte) []byte {
+	z.open()
+	return z.encoder.EncodeAll(src, dst)
+}
+
+func (z *zstdCodec) decompress(src []byte) ([]byte, error) {
+	// The zstd decoder silently returns an empty result when src doesn't contain
+	// a frame, so we need to check for one ourselves.
+	if !bytes.HasPrefix(src, zstdMagic) {
+		return nil, errors.New("[compress] - invalid zstd frame")
+	}
+	z.open()
+	return z.decoder.DecodeAll(src, nil)
+}
+
+// open lazily allocates the encoder and decoder, which are relatively expensive to
+// create. Both are safe for concurrent use through EncodeAll and DecodeAll.
+func (z *zstdCodec) open() {
+	z.once.Do(func() {
+		var err error
+		if z.encoder, err = zstd.NewWriter(nil); err != nil {
+			panic(err)
+		}
+		if z.decoder, err = zstd.NewReader(nil); err != nil {
+			panic(err)
+		}
+	})
+}
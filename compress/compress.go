@@ -0,0 +1,80 @@
+// Package compress implements a transparent compression layer for byte values. It's
+// designed to sit between an application and a storage medium (such as a kv.DB or
+// a binary.EncoderDecoder), and to remain compatible with values written before
+// compression was introduced.
+//
+// Every value produced by a Compressor starts with a single header byte that
+// identifies how the rest of the value is stored. Values smaller than the configured
+// threshold (or values that don't shrink when compressed) are stored raw, and only
+// carry a header when their first byte collides with a reserved header byte. This
+// means that uncompressed legacy values decode to themselves, with one caveat:
+// a legacy value starting with a reserved header byte (0xC0-0xC2) will be
+// misinterpreted. Gob encoded values never start with these bytes.
+//
+package compress
+
+const (
+	// headerRaw marks a raw value whose first byte collides with a reserved header.
+	headerRaw byte = 0xC0
+	// headerSnappy marks a value compressed using the Snappy codec.
+	headerSnappy byte = 0xC1
+	// headerZstd marks a value compressed using the Zstd codec.
+	headerZstd byte = 0xC2
+)
+
+func reserved(b byte) bool { return b >= headerRaw && b <= headerZstd }
+
+// Compressor compresses and decompresses byte values. Compressor is goroutine
+// safe. To create a new Compressor, call New.
+type Compressor struct {
+	*options
+}
+
+// New creates a new Compressor with the provided options. By default, the
+// Compressor uses the Snappy codec and leaves values smaller than 256 bytes
+// uncompressed.
+func New(opts ...Option) *Compressor { return &Compressor{options: newOptions(opts...)} }
+
+// Compress compresses the provided value. If the value is smaller than the
+// Compressor's threshold, or compression does not reduce its size, the value is
+// stored raw. The returned slice may share memory with b.
+func (c *Compressor) Compress(b []byte) []byte {
+	if len(b) >= c.threshold {
+		comp := c.codec.compress([]byte{c.codec.header()}, b)
+		if len(comp) < len(b) {
+			return comp
+		}
+	}
+	if len(b) > 0 && reserved(b[0]) {
+		return append([]byte{headerRaw}, b...)
+	}
+	return b
+}
+
+// Decompress decompresses a value produced by Compress. Decompress can decode
+// values compressed with any codec, regardless of the codec the Compressor is
+// configured with. Values without a header are returned as is. If a value carries a
+// codec header but fails to decompress, it's assumed to be a legacy raw value and
+// is also returned as is (corrupted values will surface as errors when decoding the
+// returned bytes). The returned slice may share memory with b.
+func (c *Compressor) Decompress(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	var codec Codec
+	switch b[0] {
+	case headerRaw:
+		return b[1:]
+	case headerSnappy:
+		codec = Snappy
+	case headerZstd:
+		codec = Zstd
+	default:
+		return b
+	}
+	d, err := codec.decompress(b[1:])
+	if err != nil {
+		return b
+	}
+	return d
+}
@@ -0,0 +1,53 @@
+package compress_test
+
+import (
+	"bytes"
+	"github.com/arya-analytics/x/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compressor", func() {
+	large := bytes.Repeat([]byte("arya analytics "), 100)
+	for _, codec := range []compress.Codec{compress.Snappy, compress.Zstd} {
+		codec := codec
+		Context(codec.String(), func() {
+			var c *compress.Compressor
+			BeforeEach(func() { c = compress.New(compress.WithCodec(codec)) })
+			It("Should compress values above the threshold", func() {
+				comp := c.Compress(large)
+				Expect(len(comp)).To(BeNumerically("<", len(large)))
+				Expect(c.Decompress(comp)).To(Equal(large))
+			})
+			It("Should leave values below the threshold untouched", func() {
+				v := []byte("small")
+				Expect(c.Compress(v)).To(Equal(v))
+				Expect(c.Decompress(v)).To(Equal(v))
+			})
+		})
+	}
+	It("Should decompress values compressed with a different codec", func() {
+		comp := compress.New(compress.WithCodec(compress.Zstd)).Compress(large)
+		Expect(compress.New().Decompress(comp)).To(Equal(large))
+	})
+	It("Should store values that don't shrink raw", func() {
+		c := compress.New(compress.WithThreshold(0))
+		v := []byte{1, 2, 3}
+		Expect(c.Compress(v)).To(Equal(v))
+	})
+	It("Should escape raw values that start with a header byte", func() {
+		c := compress.New()
+		v := []byte{0xC1, 1, 2}
+		comp := c.Compress(v)
+		Expect(comp).To(Equal([]byte{0xC0, 0xC1, 1, 2}))
+		Expect(c.Decompress(comp)).To(Equal(v))
+	})
+	It("Should return legacy values that fail to decompress as is", func() {
+		v := []byte{0xC2, 1, 2, 3}
+		Expect(compress.New().Decompress(v)).To(Equal(v))
+	})
+	It("Should handle empty values", func() {
+		c := compress.New(compress.WithThreshold(0))
+		Expect(c.Decompress(c.Compress([]byte{}))).To(BeEmpty())
+	})
+})
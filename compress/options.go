@@ -0,0 +1,39 @@
+package compress
+
+type options struct {
+	codec     Codec
+	threshold int
+}
+
+type Option func(o *options)
+
+// WithCodec sets the codec used to compress values.
+func WithCodec(codec Codec) Option {
+	return func(o *options) { o.codec = codec }
+}
+
+// WithThreshold sets the size (in bytes) below which values are stored
+// uncompressed. A threshold of zero will compress all values.
+func WithThreshold(threshold int) Option {
+	return func(o *options) { o.threshold = threshold }
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{threshold: -1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	mergeDefaultOptions(o)
+	return o
+}
+
+const defaultThreshold = 256
+
+func mergeDefaultOptions(o *options) {
+	if o.codec == nil {
+		o.codec = Snappy
+	}
+	if o.threshold < 0 {
+		o.threshold = defaultThreshold
+	}
+}
@@ -128,6 +128,16 @@ type TransformFunc[I, O Value] struct {
 	ApplyTransform func(ctx signal.Context, i I) (o O, ok bool, err error)
 }
 
+// Transform calls ApplyTransform, handling any error it returns using the ErrorPolicy
+// defined in the provided Options. See Options.Guard for more details.
+func (t TransformFunc[I, O]) Transform(ctx signal.Context, fo *Options, i I) (o O, ok bool, err error) {
+	applied, err := fo.Guard(ctx, i, func() (err error) {
+		o, ok, err = t.ApplyTransform(ctx, i)
+		return err
+	})
+	return o, ok && applied, err
+}
+
 // Stream represents a streamImpl of values. Each streamImpl has an addressable Outlet
 // and an addressable Inlet. These addresses are best represented as unique locations where values
 // are received from (Inlet) and sent to (Outlet). It is also generally OK to share a streamImpl across multiple
@@ -35,11 +35,17 @@ type DeltaTransformMultiplier[I, O Value] struct {
 func (d *DeltaTransformMultiplier[I, O]) Flow(ctx signal.Context, opts ...Option) {
 	o := NewOptions(opts)
 	o.AttachInletCloser(d)
-	d.GoRange(ctx, d.transformAndMultiply, o.Signal...)
+	d.GoRange(ctx, func(ctx signal.Context, i I) error {
+		return d.transformAndMultiply(ctx, o, i)
+	}, o.Signal...)
 }
 
-func (d *DeltaTransformMultiplier[I, O]) transformAndMultiply(ctx signal.Context, i I) error {
-	o, ok, err := d.ApplyTransform(ctx, i)
+func (d *DeltaTransformMultiplier[I, O]) transformAndMultiply(
+	ctx signal.Context,
+	fo *Options,
+	i I,
+) error {
+	o, ok, err := d.Transform(ctx, fo, i)
 	if !ok || err != nil {
 		return err
 	}
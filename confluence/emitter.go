@@ -19,14 +19,19 @@ type Emitter[V Value] struct {
 func (e *Emitter[V]) Flow(ctx signal.Context, opts ...Option) {
 	fo := NewOptions(opts)
 	fo.AttachInletCloser(e)
-	signal.GoTick(ctx, e.Interval, e.emit, fo.Signal...)
+	signal.GoTick(ctx, e.Interval, func(ctx signal.Context, t time.Time) error {
+		return e.emit(ctx, fo, t)
+	}, fo.Signal...)
 }
 
-func (e *Emitter[V]) emit(ctx signal.Context, t time.Time) error {
-	v, err := e.Emit(ctx)
-	if err != nil {
+func (e *Emitter[V]) emit(ctx signal.Context, fo *Options, _ time.Time) error {
+	var v V
+	applied, err := fo.Guard(ctx, nil, func() (err error) {
+		v, err = e.Emit(ctx)
+		return err
+	})
+	if !applied {
 		return err
 	}
-	e.Out.Inlet() <- v
-	return nil
+	return Send(e.Out, v)
 }
@@ -0,0 +1,105 @@
+package confluence
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
+	"time"
+)
+
+// ErrorPolicy defines how a Segment handles an error returned by a user defined
+// function (i.e. TransformFunc.ApplyTransform, SwitchFunc.ApplySwitch, Emitter.Emit).
+type ErrorPolicy byte
+
+const (
+	// ErrorFatal is the default error policy. The Segment exits and returns the error
+	// as fatal to the context.
+	ErrorFatal ErrorPolicy = iota
+	// ErrorSkip discards the value that caused the error, and reports a ValueError to
+	// the context's transient error channel. The Segment blocks until the error is
+	// received from the channel or the context is cancelled.
+	ErrorSkip
+	// ErrorRetry retries the function using the Options.Retry configuration. If all
+	// retries fail, the error is fatal.
+	ErrorRetry
+	// ErrorDeadLetter sends a ValueError containing the value and the error to the
+	// Options.DeadLetter Inlet. If no Inlet is set, the error is fatal.
+	ErrorDeadLetter
+)
+
+// ValueError is an error that occurred while processing a value.
+type ValueError struct {
+	// Value is the value that caused the error. Value is nil for errors returned by
+	// Segments that don't receive values (i.e. Emitter).
+	Value Value
+	// Err is the error that occurred.
+	Err error
+}
+
+// Error implements error.
+func (v ValueError) Error() string {
+	return fmt.Sprintf("[confluence] - failed to process value %v: %s", v.Value, v.Err)
+}
+
+// Unwrap returns the underlying error.
+func (v ValueError) Unwrap() error { return v.Err }
+
+// RetryConfig configures the ErrorRetry policy.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries before the error is fatal.
+	MaxRetries int
+	// Interval is the time to wait before the first retry.
+	Interval time.Duration
+	// Scale is the factor the interval is multiplied by after each retry. A Scale less
+	// than 1 is treated as 1.
+	Scale float64
+}
+
+// Guard calls f, handling any error it returns using the ErrorPolicy defined in the
+// Options. Returns true if f succeeded. If false is returned with a nil error, the
+// value should be skipped. If a non-nil error is returned, it should be treated as
+// fatal.
+func (fo *Options) Guard(ctx signal.Context, v Value, f func() error) (bool, error) {
+	err := f()
+	if err == nil {
+		return true, nil
+	}
+	switch fo.ErrorPolicy {
+	case ErrorSkip:
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case ctx.Transient() <- ValueError{Value: v, Err: err}:
+		}
+		return false, nil
+	case ErrorRetry:
+		return fo.retry(ctx, f, err)
+	case ErrorDeadLetter:
+		if fo.DeadLetter == nil {
+			// There's nowhere to send the value, so the error is fatal.
+			return false, errors.Wrap(err, "[confluence] - no dead letter inlet to send failed value to")
+		}
+		return false, Send(fo.DeadLetter, ValueError{Value: v, Err: err})
+	}
+	return false, err
+}
+
+func (fo *Options) retry(ctx signal.Context, f func() error, err error) (bool, error) {
+	interval := fo.Retry.Interval
+	for i := 0; i < fo.Retry.MaxRetries; i++ {
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return false, ctx.Err()
+		case <-t.C:
+		}
+		if err = f(); err == nil {
+			return true, nil
+		}
+		if fo.Retry.Scale > 1 {
+			interval = time.Duration(float64(interval) * fo.Retry.Scale)
+		}
+	}
+	return false, err
+}
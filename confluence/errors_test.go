@@ -0,0 +1,134 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var errOdd = errors.New("odd")
+
+var _ = Describe("Error Policies", func() {
+	var (
+		inlet, outlet confluence.Stream[int]
+		trans         *confluence.LinearTransform[int, int]
+		ctx           signal.Context
+		cancel        context.CancelFunc
+	)
+	BeforeEach(func() {
+		inlet = confluence.NewStream[int](3)
+		outlet = confluence.NewStream[int](3)
+		trans = &confluence.LinearTransform[int, int]{}
+		trans.ApplyTransform = func(ctx signal.Context, i int) (int, bool, error) {
+			if i%2 != 0 {
+				return 0, false, errOdd
+			}
+			return i, true, nil
+		}
+		trans.InFrom(inlet)
+		trans.OutTo(outlet)
+		ctx, cancel = signal.WithCancel(context.Background())
+	})
+	AfterEach(func() { cancel() })
+	Describe("ErrorFatal", func() {
+		It("Should exit the segment and return the error to the context", func() {
+			trans.Flow(ctx)
+			inlet.Inlet() <- 1
+			Expect(ctx.Wait()).To(MatchError(errOdd))
+		})
+	})
+	Describe("ErrorSkip", func() {
+		It("Should skip the value and report it as a transient error", func() {
+			trans.Flow(ctx, confluence.SkipErrors())
+			inlet.Inlet() <- 1
+			inlet.Inlet() <- 2
+			var err error
+			Eventually(ctx.Transient()).Should(Receive(&err))
+			Expect(errors.Is(err, errOdd)).To(BeTrue())
+			Expect(<-outlet.Outlet()).To(Equal(2))
+			var vErr confluence.ValueError
+			Expect(errors.As(err, &vErr)).To(BeTrue())
+			Expect(vErr.Value).To(Equal(1))
+		})
+	})
+	Describe("ErrorRetry", func() {
+		It("Should retry the value until it succeeds", func() {
+			attempts := 0
+			trans.ApplyTransform = func(ctx signal.Context, i int) (int, bool, error) {
+				attempts++
+				if attempts < 3 {
+					return 0, false, errOdd
+				}
+				return i, true, nil
+			}
+			trans.Flow(ctx, confluence.RetryErrors(confluence.RetryConfig{
+				MaxRetries: 3,
+				Interval:   time.Millisecond,
+				Scale:      2,
+			}))
+			inlet.Inlet() <- 1
+			Expect(<-outlet.Outlet()).To(Equal(1))
+			Expect(attempts).To(Equal(3))
+		})
+		It("Should return a fatal error once retries are exhausted", func() {
+			trans.Flow(ctx, confluence.RetryErrors(confluence.RetryConfig{
+				MaxRetries: 2,
+				Interval:   time.Millisecond,
+			}))
+			inlet.Inlet() <- 1
+			Expect(ctx.Wait()).To(MatchError(errOdd))
+		})
+	})
+	Describe("ErrorDeadLetter", func() {
+		It("Should send the failed value to the dead letter inlet", func() {
+			deadLetter := confluence.NewStream[confluence.ValueError](1)
+			trans.Flow(ctx, confluence.DeadLetterErrors(deadLetter))
+			inlet.Inlet() <- 1
+			inlet.Inlet() <- 2
+			Expect(<-outlet.Outlet()).To(Equal(2))
+			vErr := <-deadLetter.Outlet()
+			Expect(vErr.Value).To(Equal(1))
+			Expect(vErr.Err).To(MatchError(errOdd))
+		})
+		It("Should return a fatal error if the dead letter inlet is nil", func() {
+			trans.Flow(ctx, confluence.DeadLetterErrors(nil))
+			inlet.Inlet() <- 1
+			err := ctx.Wait()
+			Expect(err).To(MatchError(ContainSubstring("dead letter inlet")))
+			Expect(errors.Is(err, errOdd)).To(BeTrue())
+		})
+		It("Should return a fatal error if no dead letter inlet is set", func() {
+			trans.Flow(ctx, func(fo *confluence.Options) {
+				fo.ErrorPolicy = confluence.ErrorDeadLetter
+			})
+			inlet.Inlet() <- 1
+			err := ctx.Wait()
+			Expect(err).To(MatchError(ContainSubstring("dead letter inlet")))
+			Expect(errors.Is(err, errOdd)).To(BeTrue())
+		})
+	})
+	Describe("Emitter", func() {
+		It("Should apply the error policy to errors returned by Emit", func() {
+			emitOut := confluence.NewStream[int](1)
+			count := 0
+			e := &confluence.Emitter[int]{
+				Interval: time.Millisecond,
+				Emit: func(ctx signal.Context) (int, error) {
+					count++
+					if count == 1 {
+						return 0, errOdd
+					}
+					return count, nil
+				},
+			}
+			e.OutTo(emitOut)
+			e.Flow(ctx, confluence.SkipErrors())
+			Eventually(ctx.Transient()).Should(Receive())
+			Expect(<-emitOut.Outlet()).To(Equal(2))
+		})
+	})
+})
@@ -32,18 +32,25 @@ func (f *Filter[V]) OutTo(inlets ...Inlet[V]) {
 func (f *Filter[V]) Flow(ctx signal.Context, opts ...Option) {
 	fo := NewOptions(opts)
 	fo.AttachInletCloser(f)
-	f.GoRange(ctx, f.filter, fo.Signal...)
+	f.GoRange(ctx, func(ctx signal.Context, v V) error {
+		return f.filter(ctx, fo, v)
+	}, fo.Signal...)
 }
 
-func (f *Filter[V]) filter(ctx signal.Context, v V) error {
-	ok, err := f.Apply(ctx, v)
-	if err != nil {
+func (f *Filter[V]) filter(ctx signal.Context, fo *Options, v V) error {
+	var ok bool
+	applied, err := fo.Guard(ctx, v, func() (err error) {
+		ok, err = f.Apply(ctx, v)
+		return err
+	})
+	if !applied {
 		return err
 	}
 	if ok {
-		f.AbstractLinear.Out.Inlet() <- v
-	} else if f.Rejects != nil {
-		f.Rejects.Inlet() <- v
+		return Send(f.AbstractLinear.Out, v)
+	}
+	if f.Rejects != nil {
+		return Send(f.Rejects, v)
 	}
 	return nil
 }
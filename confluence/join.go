@@ -0,0 +1,136 @@
+package confluence
+
+import (
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/telem"
+)
+
+// Join is a Segment that pairs values from two Outlet(s) that share the same key and
+// whose timestamps are within Window of each other. The first Outlet provided to
+// InFrom is the left side of the join, and the second is the right side. Every
+// matching pair is passed to Apply, and the result is sent to the Segment's Inlet.
+//
+// Join buffers values until they can no longer match a value from the other side
+// i.e. once the other side receives a value whose timestamp is more than Window after
+// the buffered value. Each side keeps its own watermark, so one side can run ahead
+// of the other without its values being evicted early. Join exits once both of its
+// Outlet(s) are closed.
+type Join[V Value, K comparable, O Value] struct {
+	AbstractMultiSink[V]
+	AbstractUnarySource[O]
+	// Key extracts the join key from a value.
+	Key func(v V) K
+	// Timestamp extracts the timestamp of a value.
+	Timestamp func(v V) telem.TimeStamp
+	// Window is the maximum difference between the timestamps of two paired values.
+	Window telem.TimeSpan
+	// Apply is called with each matching pair of values. If it returns false, no
+	// result is sent for the pair. If it returns an error, the Join exits and returns
+	// a fatal error to the context.
+	Apply func(ctx signal.Context, left, right V) (O, bool, error)
+}
+
+// InFrom implements the Sink interface.
+func (j *Join[V, K, O]) InFrom(outlets ...Outlet[V]) {
+	j.AbstractMultiSink.InFrom(outlets...)
+	if len(j.In) > 2 {
+		panic("[confluence.Join] - must have exactly two outlets")
+	}
+}
+
+// Flow implements the Segment interface.
+func (j *Join[V, K, O]) Flow(ctx signal.Context, opts ...Option) {
+	if len(j.In) != 2 {
+		panic("[confluence.Join] - must have exactly two outlets")
+	}
+	o := NewOptions(opts)
+	o.AttachInletCloser(j)
+	ctx.Go(j.join, o.Signal...)
+}
+
+func (j *Join[V, K, O]) join(ctx signal.Context) error {
+	var (
+		buffers = [2]map[K][]V{make(map[K][]V), make(map[K][]V)}
+		outlets = [2]<-chan V{j.In[0].Outlet(), j.In[1].Outlet()}
+		// watermarks holds the latest timestamp received on each side.
+		watermarks [2]telem.TimeStamp
+		seen       [2]bool
+	)
+	for outlets[0] != nil || outlets[1] != nil {
+		var (
+			v    V
+			ok   bool
+			side int
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok = <-outlets[0]:
+			side = 0
+		case v, ok = <-outlets[1]:
+			side = 1
+		}
+		if !ok {
+			outlets[side] = nil
+			continue
+		}
+		var (
+			key = j.Key(v)
+			ts  = j.Timestamp(v)
+		)
+		for _, other := range buffers[1-side][key] {
+			if !j.withinWindow(ts, j.Timestamp(other)) {
+				continue
+			}
+			left, right := v, other
+			if side == 1 {
+				left, right = other, v
+			}
+			if err := j.apply(ctx, left, right); err != nil {
+				return err
+			}
+		}
+		buffers[side][key] = append(buffers[side][key], v)
+		if !seen[side] || ts.After(watermarks[side]) {
+			watermarks[side], seen[side] = ts, true
+			// Buffered values from the other side can only match future values from
+			// this side, so they're evicted using this side's watermark.
+			j.evict(buffers[1-side], watermarks[side])
+		}
+	}
+	return nil
+}
+
+func (j *Join[V, K, O]) withinWindow(a, b telem.TimeStamp) bool {
+	diff := telem.TimeSpan(a - b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= j.Window
+}
+
+func (j *Join[V, K, O]) apply(ctx signal.Context, left, right V) error {
+	o, ok, err := j.Apply(ctx, left, right)
+	if err != nil || !ok {
+		return err
+	}
+	return Send(j.Out, o)
+}
+
+// evict removes buffered values that are too old to match any future value.
+func (j *Join[V, K, O]) evict(buffer map[K][]V, watermark telem.TimeStamp) {
+	threshold := watermark.Sub(j.Window)
+	for key, values := range buffer {
+		remaining := values[:0]
+		for _, v := range values {
+			if j.Timestamp(v).AfterEq(threshold) {
+				remaining = append(remaining, v)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(buffer, key)
+		} else {
+			buffer[key] = remaining
+		}
+	}
+}
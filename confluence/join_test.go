@@ -0,0 +1,93 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/telem"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type keyedSample struct {
+	key   string
+	ts    telem.TimeStamp
+	value int
+}
+
+var _ = Describe("Join", func() {
+	It("Should pair values with matching keys within the window", func() {
+		left, right := confluence.NewStream[keyedSample](5), confluence.NewStream[keyedSample](5)
+		outlet := confluence.NewStream[[2]int](5)
+		join := &confluence.Join[keyedSample, string, [2]int]{
+			Key:       func(s keyedSample) string { return s.key },
+			Timestamp: func(s keyedSample) telem.TimeStamp { return s.ts },
+			Window:    5,
+			Apply: func(_ signal.Context, l, r keyedSample) ([2]int, bool, error) {
+				return [2]int{l.value, r.value}, true, nil
+			},
+		}
+		join.InFrom(left, right)
+		join.OutTo(outlet)
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		join.Flow(ctx, confluence.CloseInletsOnExit())
+		left.Inlet() <- keyedSample{key: "a", ts: 1, value: 1}
+		left.Inlet() <- keyedSample{key: "b", ts: 2, value: 2}
+		Eventually(func() int { return len(left.Outlet()) }).Should(BeZero())
+		right.Inlet() <- keyedSample{key: "a", ts: 4, value: 10}
+		right.Inlet() <- keyedSample{key: "c", ts: 4, value: 20}
+		Expect(<-outlet.Outlet()).To(Equal([2]int{1, 10}))
+		right.Inlet() <- keyedSample{key: "b", ts: 20, value: 30}
+		left.Close()
+		right.Close()
+		var remaining [][2]int
+		for v := range outlet.Outlet() {
+			remaining = append(remaining, v)
+		}
+		Expect(remaining).To(BeEmpty())
+		Expect(ctx.Wait()).To(Succeed())
+	})
+	It("Should pair values when one side runs ahead of the other", func() {
+		left, right := confluence.NewStream[keyedSample](20), confluence.NewStream[keyedSample](20)
+		outlet := confluence.NewStream[[2]int](20)
+		join := &confluence.Join[keyedSample, int, [2]int]{
+			Key:       func(s keyedSample) int { return s.value },
+			Timestamp: func(s keyedSample) telem.TimeStamp { return s.ts },
+			Window:    5,
+			Apply: func(_ signal.Context, l, r keyedSample) ([2]int, bool, error) {
+				return [2]int{l.value, r.value}, true, nil
+			},
+		}
+		join.InFrom(left, right)
+		join.OutTo(outlet)
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		join.Flow(ctx, confluence.CloseInletsOnExit())
+		for i := 0; i < 20; i++ {
+			left.Inlet() <- keyedSample{ts: telem.TimeStamp(i), value: i}
+		}
+		Eventually(func() int { return len(left.Outlet()) }).Should(BeZero())
+		for i := 0; i < 20; i++ {
+			right.Inlet() <- keyedSample{ts: telem.TimeStamp(i), value: i}
+		}
+		left.Close()
+		right.Close()
+		var pairs [][2]int
+		for v := range outlet.Outlet() {
+			pairs = append(pairs, v)
+		}
+		Expect(pairs).To(HaveLen(20))
+		for i, p := range pairs {
+			Expect(p).To(Equal([2]int{i, i}))
+		}
+		Expect(ctx.Wait()).To(Succeed())
+	})
+	It("Should panic when not provided exactly two outlets", func() {
+		join := &confluence.Join[keyedSample, string, int]{}
+		join.InFrom(confluence.NewStream[keyedSample](0))
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		Expect(func() { join.Flow(ctx) }).To(Panic())
+	})
+})
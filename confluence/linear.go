@@ -23,14 +23,15 @@ type LinearTransform[I, O Value] struct {
 func (l *LinearTransform[I, O]) Flow(ctx signal.Context, opts ...Option) {
 	o := NewOptions(opts)
 	o.AttachInletCloser(l)
-	l.GoRange(ctx, l.transform, o.Signal...)
+	l.GoRange(ctx, func(ctx signal.Context, i I) error {
+		return l.transform(ctx, o, i)
+	}, o.Signal...)
 }
 
-func (l *LinearTransform[I, O]) transform(ctx signal.Context, i I) error {
-	v, ok, err := l.ApplyTransform(ctx, i)
+func (l *LinearTransform[I, O]) transform(ctx signal.Context, fo *Options, i I) error {
+	v, ok, err := l.Transform(ctx, fo, i)
 	if err != nil || !ok {
 		return err
 	}
-	l.AbstractUnarySource.Out.Inlet() <- v
-	return nil
+	return Send(l.AbstractUnarySource.Out, v)
 }
@@ -0,0 +1,100 @@
+package confluence
+
+import (
+	"github.com/arya-analytics/x/signal"
+	"reflect"
+)
+
+// Merge is a Segment that interleaves the values from multiple Outlet(s) into a single
+// Inlet. When more than one Outlet has a value ready, one is chosen at random, so
+// that a busy Outlet can't starve the others. Merge exits once all of its Outlet(s)
+// are closed.
+type Merge[V Value] struct {
+	AbstractMultiSink[V]
+	AbstractUnarySource[V]
+}
+
+// Flow implements the Segment interface.
+func (m *Merge[V]) Flow(ctx signal.Context, opts ...Option) {
+	o := NewOptions(opts)
+	o.AttachInletCloser(m)
+	ctx.Go(m.merge, o.Signal...)
+}
+
+func (m *Merge[V]) merge(ctx signal.Context) error {
+	cases := make([]reflect.SelectCase, len(m.In)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	for i, outlet := range m.In {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(outlet.Outlet())}
+	}
+	for open := len(m.In); open > 0; {
+		i, v, ok := reflect.Select(cases)
+		if i == 0 {
+			return ctx.Err()
+		}
+		if !ok {
+			// A nil channel is never selected, so we use it to remove closed
+			// outlets from the selection.
+			cases[i].Chan = reflect.ValueOf(nil)
+			open--
+			continue
+		}
+		if err := Send(m.Out, v.Interface().(V)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OrderedMerge is a Segment that merges values from multiple Outlet(s) into a single
+// Inlet in the order defined by Less. Each Outlet must emit values in order. To do
+// this, OrderedMerge waits until every open Outlet has a value ready before emitting
+// the lowest one, so a single idle Outlet stalls the merge. OrderedMerge exits once
+// all of its Outlet(s) are closed.
+type OrderedMerge[V Value] struct {
+	AbstractMultiSink[V]
+	AbstractUnarySource[V]
+	// Less returns true if a should be emitted before b.
+	Less func(a, b V) bool
+}
+
+// Flow implements the Segment interface.
+func (m *OrderedMerge[V]) Flow(ctx signal.Context, opts ...Option) {
+	o := NewOptions(opts)
+	o.AttachInletCloser(m)
+	ctx.Go(m.merge, o.Signal...)
+}
+
+func (m *OrderedMerge[V]) merge(ctx signal.Context) error {
+	var (
+		heads  = make([]V, len(m.In))
+		filled = make([]bool, len(m.In))
+		closed = make([]bool, len(m.In))
+	)
+	for {
+		for i, outlet := range m.In {
+			if filled[i] || closed[i] {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case v, ok := <-outlet.Outlet():
+				heads[i], filled[i], closed[i] = v, ok, !ok
+			}
+		}
+		next := -1
+		for i := range heads {
+			if filled[i] && (next == -1 || m.Less(heads[i], heads[next])) {
+				next = i
+			}
+		}
+		if next == -1 {
+			return nil
+		}
+		if err := Send(m.Out, heads[next]); err != nil {
+			return err
+		}
+		filled[next] = false
+	}
+}
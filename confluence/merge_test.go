@@ -0,0 +1,84 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+	Describe("Merge", func() {
+		It("Should interleave values from all outlets", func() {
+			inOne, inTwo := confluence.NewStream[int](3), confluence.NewStream[int](3)
+			outlet := confluence.NewStream[int](6)
+			merge := &confluence.Merge[int]{}
+			merge.InFrom(inOne, inTwo)
+			merge.OutTo(outlet)
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			merge.Flow(ctx, confluence.CloseInletsOnExit())
+			for i := 0; i < 3; i++ {
+				inOne.Inlet() <- i
+				inTwo.Inlet() <- i + 10
+			}
+			inOne.Close()
+			inTwo.Close()
+			var values []int
+			for v := range outlet.Outlet() {
+				values = append(values, v)
+			}
+			Expect(values).To(ConsistOf(0, 1, 2, 10, 11, 12))
+			Expect(ctx.Wait()).To(Succeed())
+		})
+		It("Should not starve an outlet when another is always ready", func() {
+			busy, quiet := confluence.NewStream[int](100), confluence.NewStream[int](1)
+			outlet := confluence.NewStream[int](101)
+			merge := &confluence.Merge[int]{}
+			merge.InFrom(busy, quiet)
+			merge.OutTo(outlet)
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			merge.Flow(ctx)
+			for i := 0; i < 100; i++ {
+				busy.Inlet() <- 1
+			}
+			quiet.Inlet() <- 2
+			received := 0
+			for v := range outlet.Outlet() {
+				received++
+				if v == 2 {
+					break
+				}
+			}
+			Expect(received).To(BeNumerically("<", 100))
+		})
+	})
+	Describe("OrderedMerge", func() {
+		It("Should merge sorted outlets in order", func() {
+			inOne, inTwo := confluence.NewStream[int](3), confluence.NewStream[int](3)
+			outlet := confluence.NewStream[int](6)
+			merge := &confluence.OrderedMerge[int]{Less: func(a, b int) bool { return a < b }}
+			merge.InFrom(inOne, inTwo)
+			merge.OutTo(outlet)
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			merge.Flow(ctx, confluence.CloseInletsOnExit())
+			for _, v := range []int{1, 4, 5} {
+				inOne.Inlet() <- v
+			}
+			for _, v := range []int{2, 3, 6} {
+				inTwo.Inlet() <- v
+			}
+			inOne.Close()
+			inTwo.Close()
+			var values []int
+			for v := range outlet.Outlet() {
+				values = append(values, v)
+			}
+			Expect(values).To(Equal([]int{1, 2, 3, 4, 5, 6}))
+			Expect(ctx.Wait()).To(Succeed())
+		})
+	})
+})
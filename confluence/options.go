@@ -8,6 +8,16 @@ import (
 type Options struct {
 	Signal            []signal.GoOption
 	CloseInletsOnExit bool
+	// ErrorPolicy is how the Segment handles errors returned by user defined
+	// functions. See ErrorPolicy for more details.
+	ErrorPolicy ErrorPolicy
+	// Retry configures the ErrorRetry policy.
+	Retry RetryConfig
+	// DeadLetter receives failed values when using the ErrorDeadLetter policy.
+	DeadLetter Inlet[ValueError]
+	// Workers is the number of goroutines used by Segments that process values in
+	// parallel (i.e. ParallelTransform).
+	Workers int
 }
 
 func (fo *Options) AttachInletCloser(closer InletCloser) {
@@ -37,3 +47,33 @@ func WithAddress(addr address.Address) Option {
 func CloseInletsOnExit() Option {
 	return func(fo *Options) { fo.CloseInletsOnExit = true }
 }
+
+// SkipErrors sets the Segment to discard values that cause an error, reporting the
+// error to the context's transient error channel.
+func SkipErrors() Option {
+	return func(fo *Options) { fo.ErrorPolicy = ErrorSkip }
+}
+
+// RetryErrors sets the Segment to retry values that cause an error using the provided
+// configuration.
+func RetryErrors(cfg RetryConfig) Option {
+	return func(fo *Options) {
+		fo.ErrorPolicy = ErrorRetry
+		fo.Retry = cfg
+	}
+}
+
+// DeadLetterErrors sets the Segment to send values that cause an error to the
+// provided Inlet. If the Inlet is nil, errors are fatal.
+func DeadLetterErrors(inlet Inlet[ValueError]) Option {
+	return func(fo *Options) {
+		fo.ErrorPolicy = ErrorDeadLetter
+		fo.DeadLetter = inlet
+	}
+}
+
+// WithWorkers sets the number of goroutines used by Segments that process values in
+// parallel.
+func WithWorkers(n int) Option {
+	return func(fo *Options) { fo.Workers = n }
+}
@@ -0,0 +1,168 @@
+package confluence
+
+import (
+	"github.com/arya-analytics/x/signal"
+	"runtime"
+	"sync/atomic"
+)
+
+// ParallelTransform is a Segment that applies a TransformFunc to values using a pool
+// of worker goroutines. The number of workers is set using the WithWorkers option,
+// and defaults to runtime.GOMAXPROCS. By default, results are sent in the order their
+// inputs were received. If Unordered is true, results are sent as soon as they're
+// ready, which improves throughput when transform times vary.
+//
+// ApplyTransform is called concurrently, so it must be safe for concurrent use.
+type ParallelTransform[I, O Value] struct {
+	AbstractLinear[I, O]
+	TransformFunc[I, O]
+	// Unordered disables the ordering of results.
+	Unordered bool
+}
+
+type parallelResult[O Value] struct {
+	value O
+	ok    bool
+	err   error
+}
+
+type parallelJob[I, O Value] struct {
+	value  I
+	result chan parallelResult[O]
+}
+
+// Flow implements the Segment interface.
+func (p *ParallelTransform[I, O]) Flow(ctx signal.Context, opts ...Option) {
+	o := NewOptions(opts)
+	o.AttachInletCloser(p)
+	workers := o.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		jobs = make(chan parallelJob[I, O], workers)
+		// pending holds the result channel of each in-flight job in input order.
+		// It's only used when results are ordered.
+		pending = make(chan chan parallelResult[O], workers)
+		// results receives results as soon as they're ready. It's only used when
+		// results are unordered.
+		results = make(chan parallelResult[O], workers)
+		running = int32(workers)
+	)
+	ctx.Go(func(ctx signal.Context) error {
+		defer close(jobs)
+		defer close(pending)
+		return p.dispatch(ctx, jobs, pending)
+	})
+	for i := 0; i < workers; i++ {
+		ctx.Go(func(ctx signal.Context) error {
+			defer func() {
+				if atomic.AddInt32(&running, -1) == 0 {
+					close(results)
+				}
+			}()
+			return p.work(ctx, o, jobs, results)
+		})
+	}
+	ctx.Go(func(ctx signal.Context) error {
+		if p.Unordered {
+			return p.collect(ctx, results)
+		}
+		return p.collectOrdered(ctx, pending)
+	}, o.Signal...)
+}
+
+func (p *ParallelTransform[I, O]) dispatch(
+	ctx signal.Context,
+	jobs chan<- parallelJob[I, O],
+	pending chan<- chan parallelResult[O],
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-p.In.Outlet():
+			if !ok {
+				return nil
+			}
+			job := parallelJob[I, O]{value: v, result: make(chan parallelResult[O], 1)}
+			if !p.Unordered {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case pending <- job.result:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobs <- job:
+			}
+		}
+	}
+}
+
+func (p *ParallelTransform[I, O]) work(
+	ctx signal.Context,
+	fo *Options,
+	jobs <-chan parallelJob[I, O],
+	results chan<- parallelResult[O],
+) error {
+	for job := range jobs {
+		var r parallelResult[O]
+		r.value, r.ok, r.err = p.Transform(ctx, fo, job.value)
+		var out chan<- parallelResult[O] = job.result
+		if p.Unordered {
+			out = results
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- r:
+		}
+	}
+	return nil
+}
+
+func (p *ParallelTransform[I, O]) collectOrdered(
+	ctx signal.Context,
+	pending <-chan chan parallelResult[O],
+) error {
+	for result := range pending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-result:
+			if err := p.send(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *ParallelTransform[I, O]) collect(
+	ctx signal.Context,
+	results <-chan parallelResult[O],
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r, ok := <-results:
+			if !ok {
+				return nil
+			}
+			if err := p.send(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *ParallelTransform[I, O]) send(r parallelResult[O]) error {
+	if r.err != nil || !r.ok {
+		return r.err
+	}
+	return Send(p.Out, r.value)
+}
@@ -0,0 +1,64 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("ParallelTransform", func() {
+	var (
+		inlet, outlet confluence.Stream[int]
+		trans         *confluence.ParallelTransform[int, int]
+		ctx           signal.Context
+		cancel        context.CancelFunc
+	)
+	BeforeEach(func() {
+		inlet = confluence.NewStream[int](10)
+		outlet = confluence.NewStream[int](10)
+		trans = &confluence.ParallelTransform[int, int]{}
+		// Lower values take longer to transform, so that workers finish out of
+		// order.
+		trans.ApplyTransform = func(ctx signal.Context, i int) (int, bool, error) {
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			return i * i, i != 5, nil
+		}
+		trans.InFrom(inlet)
+		trans.OutTo(outlet)
+		ctx, cancel = signal.WithCancel(context.Background())
+	})
+	AfterEach(func() { cancel() })
+	collect := func() []int {
+		for i := 0; i < 10; i++ {
+			inlet.Inlet() <- i
+		}
+		inlet.Close()
+		var values []int
+		for v := range outlet.Outlet() {
+			values = append(values, v)
+		}
+		Expect(ctx.Wait()).To(Succeed())
+		return values
+	}
+	It("Should transform values in parallel while preserving their order", func() {
+		trans.Flow(ctx, confluence.WithWorkers(4), confluence.CloseInletsOnExit())
+		Expect(collect()).To(Equal([]int{0, 1, 4, 9, 16, 36, 49, 64, 81}))
+	})
+	It("Should transform every value in unordered mode", func() {
+		trans.Unordered = true
+		trans.Flow(ctx, confluence.WithWorkers(10), confluence.CloseInletsOnExit())
+		Expect(collect()).To(ConsistOf(0, 1, 4, 9, 16, 36, 49, 64, 81))
+	})
+	It("Should return a fatal error when the transform fails", func() {
+		trans.ApplyTransform = func(ctx signal.Context, i int) (int, bool, error) {
+			return 0, false, errors.New("failed")
+		}
+		trans.Flow(ctx, confluence.WithWorkers(2))
+		inlet.Inlet() <- 1
+		Expect(ctx.Wait()).To(MatchError("failed"))
+	})
+})
@@ -0,0 +1,292 @@
+package plumber
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	cfs "github.com/arya-analytics/x/confluence"
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v2"
+	"strings"
+	"time"
+)
+
+// UnknownType is returned when a Spec references a segment or value type that hasn't
+// been registered.
+var UnknownType = errors.New("[plumber] - unknown type")
+
+// Spec is a declarative description of a Pipeline. It can be loaded from JSON or YAML
+// using Registry.LoadJSON or Registry.LoadYAML i.e.
+//
+//	segments:
+//	  - address: source
+//	    type: counter
+//	    params:
+//	      start: 1
+//	  - address: sink
+//	    type: printer
+//	routes:
+//	  - type: int
+//	    sources: [source]
+//	    sinks: [sink]
+//	    capacity: 10
+//	    policy:
+//	      overflow: drop_oldest
+type Spec struct {
+	Segments []SegmentSpec `json:"segments"`
+	Routes   []RouteSpec   `json:"routes"`
+}
+
+// SegmentSpec describes a source, sink, or segment in a Pipeline.
+type SegmentSpec struct {
+	Address address.Address `json:"address"`
+	// Type is the name of the Factory used to construct the segment.
+	Type string `json:"type"`
+	// Params are passed to the Factory.
+	Params Params `json:"params"`
+}
+
+// RouteSpec describes a set of streams routed between segments. A RouteSpec is
+// equivalent to a MultiRouter.
+type RouteSpec struct {
+	// Type is the name of the value type sent over the routed streams. It must be
+	// registered using RegisterValue.
+	Type     string            `json:"type"`
+	Sources  []address.Address `json:"sources"`
+	Sinks    []address.Address `json:"sinks"`
+	Capacity int               `json:"capacity"`
+	// Stitch is one of "unary" (default), "weave", or "convergent".
+	Stitch Stitch `json:"stitch"`
+	// Policy is the overflow policy applied to the routed streams.
+	Policy PolicySpec `json:"policy"`
+}
+
+// PolicySpec describes the confluence.Policy applied to the streams of a RouteSpec.
+type PolicySpec struct {
+	// Overflow is one of "block" (default), "drop_newest", "drop_oldest", or
+	// "timeout".
+	Overflow string `json:"overflow"`
+	// Timeout is the maximum duration a sender blocks for when using the "timeout"
+	// overflow, in the format accepted by time.ParseDuration (i.e. "10ms").
+	Timeout string `json:"timeout"`
+}
+
+var overflows = map[string]cfs.Overflow{
+	"":            cfs.OverflowBlock,
+	"block":       cfs.OverflowBlock,
+	"drop_newest": cfs.OverflowDropNewest,
+	"drop_oldest": cfs.OverflowDropOldest,
+	"timeout":     cfs.OverflowTimeout,
+}
+
+func (ps PolicySpec) policy() (p cfs.Policy, err error) {
+	overflow, ok := overflows[strings.ToLower(ps.Overflow)]
+	if !ok {
+		return p, errors.Newf("[plumber] - invalid overflow %q", ps.Overflow)
+	}
+	p.Overflow = overflow
+	if ps.Timeout != "" {
+		if p.Timeout, err = time.ParseDuration(ps.Timeout); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// Params are the parameters of a SegmentSpec.
+type Params map[string]interface{}
+
+// Decode decodes the Params into the provided pointer using the field names and
+// tags of encoding/json.
+func (p Params) Decode(v interface{}) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+var stitches = map[string]Stitch{
+	"":           StitchUnary,
+	"unary":      StitchUnary,
+	"weave":      StitchWeave,
+	"convergent": StitchConvergent,
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Stitch) UnmarshalText(text []byte) error {
+	st, ok := stitches[strings.ToLower(string(text))]
+	if !ok {
+		return errors.Newf("[plumber] - invalid stitch %q", text)
+	}
+	*s = st
+	return nil
+}
+
+// Factory adds the segment described by the provided address and params to the
+// Pipeline. Factories are typically registered using RegisterSource, RegisterSink,
+// or RegisterSegment, which handle setting the segment with the correct types.
+type Factory func(p *Pipeline, addr address.Address, params Params) error
+
+// Registry holds the segment factories and value types used to build a Pipeline from
+// a Spec. A Registry is not safe for concurrent registration.
+type Registry struct {
+	factories map[string]Factory
+	routes    map[string]func(p *Pipeline, r RouteSpec) error
+}
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+		routes:    make(map[string]func(p *Pipeline, r RouteSpec) error),
+	}
+}
+
+// Register registers the Factory under the provided type name, replacing any
+// existing Factory.
+func (r *Registry) Register(typ string, f Factory) { r.factories[typ] = f }
+
+// RegisterSource registers a Factory that constructs a source of values of type V.
+func RegisterSource[V cfs.Value](
+	r *Registry,
+	typ string,
+	f func(params Params) (cfs.Source[V], error),
+	opts ...cfs.Option,
+) {
+	r.Register(typ, func(p *Pipeline, addr address.Address, params Params) error {
+		source, err := f(params)
+		if err != nil {
+			return err
+		}
+		SetSource[V](p, addr, source, opts...)
+		return nil
+	})
+}
+
+// RegisterSink registers a Factory that constructs a sink of values of type V.
+func RegisterSink[V cfs.Value](
+	r *Registry,
+	typ string,
+	f func(params Params) (cfs.Sink[V], error),
+	opts ...cfs.Option,
+) {
+	r.Register(typ, func(p *Pipeline, addr address.Address, params Params) error {
+		sink, err := f(params)
+		if err != nil {
+			return err
+		}
+		SetSink[V](p, addr, sink, opts...)
+		return nil
+	})
+}
+
+// RegisterSegment registers a Factory that constructs a segment that receives values
+// of type I and sends values of type O.
+func RegisterSegment[I, O cfs.Value](
+	r *Registry,
+	typ string,
+	f func(params Params) (cfs.Segment[I, O], error),
+	opts ...cfs.Option,
+) {
+	r.Register(typ, func(p *Pipeline, addr address.Address, params Params) error {
+		seg, err := f(params)
+		if err != nil {
+			return err
+		}
+		SetSegment[I, O](p, addr, seg, opts...)
+		return nil
+	})
+}
+
+// RegisterValue registers V under the provided name so that it can be used as the
+// type of a RouteSpec.
+func RegisterValue[V cfs.Value](r *Registry, name string) {
+	r.routes[name] = func(p *Pipeline, rs RouteSpec) error {
+		policy, err := rs.Policy.policy()
+		if err != nil {
+			return err
+		}
+		return MultiRouter[V]{
+			SourceTargets: rs.Sources,
+			SinkTargets:   rs.Sinks,
+			Capacity:      rs.Capacity,
+			Stitch:        rs.Stitch,
+			Policy:        policy,
+		}.Route(p)
+	}
+}
+
+// Build constructs a new Pipeline from the provided Spec. Segments are added in the
+// order they're specified, followed by routes.
+func (r *Registry) Build(spec Spec, opts ...Option) (*Pipeline, error) {
+	p := New(opts...)
+	for _, s := range spec.Segments {
+		f, ok := r.factories[s.Type]
+		if !ok {
+			return nil, errors.Wrapf(UnknownType, "segment %s has type %q", s.Address, s.Type)
+		}
+		if _, ok := p.Sources[s.Address]; ok {
+			return nil, errors.Newf("[plumber] - duplicate segment address %s", s.Address)
+		}
+		if _, ok := p.Sinks[s.Address]; ok {
+			return nil, errors.Newf("[plumber] - duplicate segment address %s", s.Address)
+		}
+		if err := f(p, s.Address, s.Params); err != nil {
+			return nil, errors.Wrapf(err, "failed to construct segment %s", s.Address)
+		}
+	}
+	for i, rs := range spec.Routes {
+		route, ok := r.routes[rs.Type]
+		if !ok {
+			return nil, errors.Wrapf(UnknownType, "route %d has value type %q", i, rs.Type)
+		}
+		if err := route(p, rs); err != nil {
+			return nil, errors.Wrapf(err, "failed to construct route %d", i)
+		}
+	}
+	return p, nil
+}
+
+// LoadJSON builds a new Pipeline from the JSON encoded Spec.
+func (r *Registry) LoadJSON(data []byte, opts ...Option) (*Pipeline, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return r.Build(spec, opts...)
+}
+
+// LoadYAML builds a new Pipeline from the YAML encoded Spec. The YAML is decoded
+// using the same field names as LoadJSON.
+func (r *Registry) LoadYAML(data []byte, opts ...Option) (*Pipeline, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(normalizeYAML(raw))
+	if err != nil {
+		return nil, err
+	}
+	return r.LoadJSON(b, opts...)
+}
+
+// normalizeYAML converts the maps decoded by yaml.v2 (which have interface{} keys)
+// into maps with string keys so that they can be encoded as JSON.
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeYAML(val)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,162 @@
+package plumber_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/confluence/plumber"
+	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type multiplyParams struct {
+	Factor int `json:"factor"`
+}
+
+var _ = Describe("Config", func() {
+	var (
+		reg    *plumber.Registry
+		input  confluence.Stream[int]
+		output confluence.Stream[int]
+	)
+	BeforeEach(func() {
+		reg = plumber.NewRegistry()
+		input = confluence.NewStream[int](10)
+		output = confluence.NewStream[int](10)
+		plumber.RegisterValue[int](reg, "int")
+		plumber.RegisterSource[int](reg, "input", func(plumber.Params) (confluence.Source[int], error) {
+			return &confluence.Emitter[int]{}, nil
+		})
+		plumber.RegisterSegment[int, int](reg, "multiply", func(params plumber.Params) (confluence.Segment[int, int], error) {
+			var p multiplyParams
+			if err := params.Decode(&p); err != nil {
+				return nil, err
+			}
+			t := &confluence.LinearTransform[int, int]{}
+			t.ApplyTransform = func(ctx signal.Context, v int) (int, bool, error) {
+				return v * p.Factor, true, nil
+			}
+			return t, nil
+		})
+	})
+	run := func(pipe *plumber.Pipeline) {
+		seg, err := plumber.GetSegment[int, int](pipe, "multiply")
+		Expect(err).ToNot(HaveOccurred())
+		seg.InFrom(input)
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		source, err := plumber.GetSource[int](pipe, "double")
+		Expect(err).ToNot(HaveOccurred())
+		source.OutTo(output)
+		pipe.Flow(ctx)
+		input.Inlet() <- 3
+		Expect(<-output.Outlet()).To(Equal(18))
+	}
+	Describe("LoadYAML", func() {
+		It("Should build a pipeline from a YAML spec", func() {
+			pipe, err := reg.LoadYAML([]byte(`
+segments:
+  - address: multiply
+    type: multiply
+    params:
+      factor: 3
+  - address: double
+    type: multiply
+    params:
+      factor: 2
+routes:
+  - type: int
+    sources: [multiply]
+    sinks: [double]
+    capacity: 1
+    stitch: weave
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pipe.Describe().Edges).To(HaveLen(1))
+			run(pipe)
+		})
+	})
+	Describe("LoadJSON", func() {
+		It("Should build a pipeline from a JSON spec", func() {
+			pipe, err := reg.LoadJSON([]byte(`{
+	"segments": [
+		{"address": "multiply", "type": "multiply", "params": {"factor": 3}},
+		{"address": "double", "type": "multiply", "params": {"factor": 2}}
+	],
+	"routes": [{"type": "int", "sources": ["multiply"], "sinks": ["double"]}]
+}`))
+			Expect(err).ToNot(HaveOccurred())
+			run(pipe)
+		})
+	})
+	Describe("Policy", func() {
+		It("Should apply the overflow policy to the routed streams", func() {
+			pipe, err := reg.LoadYAML([]byte(`
+segments:
+  - address: input
+    type: input
+  - address: multiply
+    type: multiply
+routes:
+  - type: int
+    sources: [input]
+    sinks: [multiply]
+    capacity: 1
+    policy:
+      overflow: drop_oldest
+`))
+			Expect(err).ToNot(HaveOccurred())
+			source, err := plumber.GetSource[int](pipe, "input")
+			Expect(err).ToNot(HaveOccurred())
+			out := source.(*confluence.Emitter[int]).Out
+			Expect(confluence.Send(out, 1)).To(Succeed())
+			Expect(confluence.Send(out, 2)).To(Succeed())
+			Expect(out.(confluence.PolicyStream[int]).Dropped()).To(Equal(int64(1)))
+		})
+	})
+	Describe("Errors", func() {
+		It("Should return an error when a segment type isn't registered", func() {
+			_, err := reg.Build(plumber.Spec{
+				Segments: []plumber.SegmentSpec{{Address: "a", Type: "unknown"}},
+			})
+			Expect(errors.Is(err, plumber.UnknownType)).To(BeTrue())
+		})
+		It("Should return an error when a value type isn't registered", func() {
+			_, err := reg.Build(plumber.Spec{
+				Segments: []plumber.SegmentSpec{{Address: "a", Type: "input"}},
+				Routes:   []plumber.RouteSpec{{Type: "string"}},
+			})
+			Expect(errors.Is(err, plumber.UnknownType)).To(BeTrue())
+		})
+		It("Should return an error when two segments share an address", func() {
+			_, err := reg.Build(plumber.Spec{
+				Segments: []plumber.SegmentSpec{
+					{Address: "a", Type: "input"},
+					{Address: "a", Type: "input"},
+				},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+		It("Should return an error identifying the route that failed to build", func() {
+			_, err := reg.Build(plumber.Spec{
+				Segments: []plumber.SegmentSpec{{Address: "a", Type: "input"}},
+				Routes: []plumber.RouteSpec{
+					{Type: "int", Sources: []address.Address{"a"}, Sinks: []address.Address{"missing"}},
+				},
+			})
+			Expect(err).To(MatchError(ContainSubstring("route 0")))
+		})
+		It("Should return an error for an invalid overflow", func() {
+			_, err := reg.Build(plumber.Spec{
+				Routes: []plumber.RouteSpec{{Type: "int", Policy: plumber.PolicySpec{Overflow: "bad"}}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid overflow")))
+		})
+		It("Should return an error for an invalid stitch", func() {
+			_, err := reg.LoadJSON([]byte(`{"routes": [{"type": "int", "stitch": "bad"}]}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
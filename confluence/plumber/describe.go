@@ -0,0 +1,74 @@
+package plumber
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"sort"
+	"strings"
+)
+
+// Description is a snapshot of the graph of segments and streams in a Pipeline.
+type Description struct {
+	// Nodes are the sources, sinks, and segments in the Pipeline sorted by address.
+	Nodes []NodeDescription
+	// Edges are the streams routed between nodes in the order they were routed.
+	Edges []EdgeDescription
+}
+
+// NodeDescription describes a source, sink, or segment in a Pipeline.
+type NodeDescription struct {
+	Address address.Address
+	// Type is the go type of the node.
+	Type string
+	// Source is true if the node can send values.
+	Source bool
+	// Sink is true if the node can receive values.
+	Sink bool
+}
+
+// EdgeDescription describes a stream routed between two nodes.
+type EdgeDescription struct {
+	Source   address.Address
+	Sink     address.Address
+	Capacity int
+}
+
+// Describe returns a Description of the Pipeline. Only streams routed using a Router
+// are included.
+func (p *Pipeline) Describe() Description {
+	nodes := make(map[address.Address]*NodeDescription)
+	node := func(addr address.Address, e entry) *NodeDescription {
+		n, ok := nodes[addr]
+		if !ok {
+			n = &NodeDescription{Address: addr, Type: fmt.Sprintf("%T", e.flow)}
+			nodes[addr] = n
+		}
+		return n
+	}
+	for addr, e := range p.Sources {
+		node(addr, e).Source = true
+	}
+	for addr, e := range p.Sinks {
+		node(addr, e).Sink = true
+	}
+	d := Description{Edges: append([]EdgeDescription(nil), p.edges...)}
+	for _, n := range nodes {
+		d.Nodes = append(d.Nodes, *n)
+	}
+	sort.Slice(d.Nodes, func(i, j int) bool { return d.Nodes[i].Address < d.Nodes[j].Address })
+	return d
+}
+
+// DOT returns a Graphviz DOT representation of the Description.
+func (d Description) DOT() string {
+	b := new(strings.Builder)
+	b.WriteString("digraph pipeline {\n")
+	for _, n := range d.Nodes {
+		_, _ = fmt.Fprintf(b, "\t%q [label=%q];\n", n.Address, fmt.Sprintf("%s\n%s", n.Address, n.Type))
+	}
+	for _, e := range d.Edges {
+		_, _ = fmt.Fprintf(b, "\t%q -> %q [label=\"%d\"];\n", e.Source, e.Sink, e.Capacity)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
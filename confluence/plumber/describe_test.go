@@ -0,0 +1,92 @@
+package plumber_test
+
+import (
+	"github.com/arya-analytics/x/alamos"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/confluence/plumber"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Describe", func() {
+	var pipe *plumber.Pipeline
+	BeforeEach(func() {
+		pipe = plumber.New()
+		plumber.SetSource[int](pipe, "source", &confluence.Emitter[int]{})
+		plumber.SetSegment[int, int](pipe, "transform", &confluence.LinearTransform[int, int]{})
+		plumber.SetSink[int](pipe, "sink", &confluence.UnarySink[int]{})
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "source",
+			SinkTarget:   "transform",
+			Capacity:     2,
+		}.Route(pipe)).To(Succeed())
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "transform",
+			SinkTarget:   "sink",
+		}.Route(pipe)).To(Succeed())
+	})
+	It("Should describe the nodes and edges of the pipeline", func() {
+		d := pipe.Describe()
+		Expect(d.Nodes).To(HaveLen(3))
+		Expect(d.Nodes[0].Address).To(BeEquivalentTo("sink"))
+		Expect(d.Nodes[0].Sink).To(BeTrue())
+		Expect(d.Nodes[0].Source).To(BeFalse())
+		Expect(d.Nodes[2].Address).To(BeEquivalentTo("transform"))
+		Expect(d.Nodes[2].Type).To(ContainSubstring("LinearTransform"))
+		Expect(d.Nodes[2].Sink && d.Nodes[2].Source).To(BeTrue())
+		Expect(d.Edges).To(Equal([]plumber.EdgeDescription{
+			{Source: "source", Sink: "transform", Capacity: 2},
+			{Source: "transform", Sink: "sink", Capacity: 0},
+		}))
+	})
+	It("Should export the description as Graphviz DOT", func() {
+		dot := pipe.Describe().DOT()
+		Expect(dot).To(HavePrefix("digraph pipeline {"))
+		Expect(dot).To(ContainSubstring(`"source" -> "transform" [label="2"];`))
+		Expect(dot).To(ContainSubstring(`"transform" -> "sink" [label="0"];`))
+	})
+})
+
+var _ = Describe("Metrics", func() {
+	It("Should record metrics for each routed stream", func() {
+		exp := alamos.New("test")
+		pipe := plumber.New(plumber.WithExperiment(exp))
+		source := &confluence.Emitter[int]{}
+		sink := &confluence.UnarySink[int]{}
+		plumber.SetSource[int](pipe, "source", source)
+		plumber.SetSink[int](pipe, "sink", sink)
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "source",
+			SinkTarget:   "sink",
+			Capacity:     2,
+		}.Route(pipe)).To(Succeed())
+		Expect(confluence.Send(source.Out, 1)).To(Succeed())
+		Expect(confluence.Send(source.Out, 2)).To(Succeed())
+		report := exp.Report()
+		Expect(report).To(HaveKey("plumber"))
+		streamReport := report["plumber"].(alamos.Report)["source->sink"].(alamos.Report)
+		Expect(streamReport).To(HaveKey("depth"))
+		Expect(streamReport["throughput"]).To(HaveKeyWithValue("value", 2))
+		Expect(streamReport["depth"]).To(HaveKeyWithValue("max", 1))
+		Expect(streamReport).To(HaveKey("send.latency"))
+	})
+	It("Should preserve the counters of policy streams", func() {
+		pipe := plumber.New(plumber.WithExperiment(alamos.New("test")))
+		source := &confluence.Emitter[int]{}
+		sink := &confluence.UnarySink[int]{}
+		plumber.SetSource[int](pipe, "source", source)
+		plumber.SetSink[int](pipe, "sink", sink)
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "source",
+			SinkTarget:   "sink",
+			Capacity:     1,
+			Policy:       confluence.Policy{Overflow: confluence.OverflowDropOldest},
+		}.Route(pipe)).To(Succeed())
+		Expect(confluence.Send(source.Out, 1)).To(Succeed())
+		Expect(confluence.Send(source.Out, 2)).To(Succeed())
+		ps, ok := source.Out.(confluence.PolicyStream[int])
+		Expect(ok).To(BeTrue())
+		Expect(ps.Dropped()).To(Equal(int64(1)))
+		Expect(ps.Blocked()).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,72 @@
+package plumber
+
+import (
+	"context"
+	cfs "github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
+)
+
+// Drainer is a handle to a Pipeline started using Pipeline.FlowDrainable. It can be
+// used to gracefully shut down the Pipeline without losing buffered values.
+type Drainer struct {
+	sources, segments             signal.Context
+	cancelSources, cancelSegments context.CancelFunc
+}
+
+// FlowDrainable starts the Pipeline in drain mode under the provided context. Sources
+// that have no inlets (i.e. entities set using SetSource, but not SetSegment) run
+// under a separate context from the rest of the Pipeline, and every entity closes its
+// inlets on exit. This allows the sources to be stopped while the rest of the
+// Pipeline processes the values already in flight. See Drainer.Drain for more
+// details.
+//
+// Cancelling the provided context stops the entire Pipeline immediately.
+func (p *Pipeline) FlowDrainable(ctx signal.Context, opts ...cfs.Option) *Drainer {
+	d := &Drainer{}
+	d.sources, d.cancelSources = signal.WithCancel(ctx)
+	d.segments, d.cancelSegments = signal.WithCancel(ctx)
+	opts = append(opts, cfs.CloseInletsOnExit())
+	for addr, e := range p.Sources {
+		sCtx := d.segments
+		if _, ok := p.Sinks[addr]; !ok {
+			sCtx = d.sources
+		}
+		e.Flow(sCtx, append(opts, cfs.WithAddress(addr))...)
+	}
+	for addr, e := range p.Sinks {
+		if _, ok := p.Sources[addr]; !ok {
+			e.Flow(d.segments, append(opts, cfs.WithAddress(addr))...)
+		}
+	}
+	return d
+}
+
+// Drain stops the Pipeline's sources, and waits for the rest of the Pipeline to
+// process any buffered values and exit. Returns the first non-cancellation error
+// encountered by any entity in the Pipeline.
+//
+// If the provided context is cancelled (or its deadline passes) before the Pipeline
+// exits, the Pipeline is cancelled immediately, and the context's error is returned
+// without waiting for its entities to exit, as an entity blocked sending to a full
+// Stream may never do so. In that case, Drain leaves behind a goroutine that waits
+// for the Pipeline, and exits once every entity does.
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.cancelSources()
+	done := make(chan error, 1)
+	go func() {
+		sErr := d.sources.Wait()
+		if errors.Is(sErr, context.Canceled) {
+			sErr = nil
+		}
+		done <- errors.CombineErrors(sErr, d.segments.Wait())
+	}()
+	select {
+	case err := <-done:
+		d.cancelSegments()
+		return err
+	case <-ctx.Done():
+		d.cancelSegments()
+		return ctx.Err()
+	}
+}
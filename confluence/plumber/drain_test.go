@@ -0,0 +1,88 @@
+package plumber_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/confluence/plumber"
+	"github.com/arya-analytics/x/signal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+// drainSink records the values received by the sink of a drained pipeline. Each spec
+// uses its own drainSink, as the sink of a cancelled pipeline may still be running
+// when the next spec starts.
+type drainSink struct {
+	mu       sync.Mutex
+	received []int
+	delay    time.Duration
+}
+
+var _ = Describe("Drain", func() {
+	var (
+		pipe *plumber.Pipeline
+		ds   *drainSink
+	)
+	BeforeEach(func() {
+		pipe = plumber.New()
+		ds = &drainSink{}
+		ds := ds
+		count := 0
+		plumber.SetSource[int](pipe, "source", &confluence.Emitter[int]{
+			Interval: time.Millisecond,
+			Emit: func(ctx signal.Context) (int, error) {
+				count++
+				return count, nil
+			},
+		})
+		trans := &confluence.LinearTransform[int, int]{}
+		trans.ApplyTransform = func(ctx signal.Context, v int) (int, bool, error) {
+			return v, true, nil
+		}
+		plumber.SetSegment[int, int](pipe, "transform", trans)
+		sink := &confluence.UnarySink[int]{}
+		sink.Sink = func(ctx signal.Context, v int) error {
+			time.Sleep(ds.delay)
+			ds.mu.Lock()
+			ds.received = append(ds.received, v)
+			ds.mu.Unlock()
+			return nil
+		}
+		plumber.SetSink[int](pipe, "sink", sink)
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "source",
+			SinkTarget:   "transform",
+			Capacity:     10,
+		}.Route(pipe)).To(Succeed())
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: "transform",
+			SinkTarget:   "sink",
+			Capacity:     10,
+		}.Route(pipe)).To(Succeed())
+	})
+	It("Should process all buffered values before exiting", func() {
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		d := pipe.FlowDrainable(ctx)
+		time.Sleep(10 * time.Millisecond)
+		Expect(d.Drain(context.Background())).To(Succeed())
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		Expect(ds.received).ToNot(BeEmpty())
+		for i, v := range ds.received {
+			Expect(v).To(Equal(i + 1))
+		}
+	})
+	It("Should cancel the pipeline when the deadline passes", func() {
+		ds.delay = 10 * time.Millisecond
+		ctx, cancel := signal.WithCancel(context.Background())
+		defer cancel()
+		d := pipe.FlowDrainable(ctx)
+		time.Sleep(20 * time.Millisecond)
+		dCtx, dCancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer dCancel()
+		Expect(d.Drain(dCtx)).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,82 @@
+package plumber
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/alamos"
+	cfs "github.com/arya-analytics/x/confluence"
+)
+
+// StreamMetrics are the metrics recorded for a stream routed through an instrumented
+// Pipeline. Metrics are recorded under a sub-experiment keyed by the source and sink
+// addresses of the stream i.e. "source->sink". Only values sent using cfs.Send are
+// recorded.
+type StreamMetrics struct {
+	// Depth tracks the number of values queued in the stream at the time of each
+	// send.
+	Depth alamos.Metric[int]
+	// Throughput tracks the number of values sent through the stream.
+	Throughput alamos.Metric[int]
+	// SendLatency tracks the time spent waiting for each value to be accepted by the
+	// stream.
+	SendLatency alamos.Duration
+}
+
+func newStreamMetrics(exp alamos.Experiment) StreamMetrics {
+	return StreamMetrics{
+		Depth:       alamos.NewGauge[int](exp, alamos.Debug, "depth"),
+		Throughput:  alamos.NewGauge[int](exp, alamos.Debug, "throughput"),
+		SendLatency: alamos.NewGaugeDuration(exp, alamos.Debug, "send.latency"),
+	}
+}
+
+func streamKey(source, sink address.Address) string {
+	return fmt.Sprintf("%s->%s", source, sink)
+}
+
+// instrumentedStream records StreamMetrics for the values sent through a stream.
+type instrumentedStream[V cfs.Value] struct {
+	cfs.Stream[V]
+	metrics StreamMetrics
+}
+
+func instrument[V cfs.Value](
+	exp alamos.Experiment,
+	source, sink address.Address,
+	stream cfs.Stream[V],
+) cfs.Stream[V] {
+	s := &instrumentedStream[V]{
+		Stream:  stream,
+		metrics: newStreamMetrics(alamos.Sub(exp, streamKey(source, sink))),
+	}
+	if ps, ok := stream.(cfs.PolicyStream[V]); ok {
+		return &instrumentedPolicyStream[V]{instrumentedStream: s, policy: ps}
+	}
+	return s
+}
+
+// Send implements cfs.InletSender.
+func (s *instrumentedStream[V]) Send(v V) error {
+	s.metrics.Depth.Record(len(s.Outlet()))
+	sw := s.metrics.SendLatency.Stopwatch()
+	sw.Start()
+	err := cfs.Send[V](s.Stream, v)
+	sw.Stop()
+	if err == nil {
+		s.metrics.Throughput.Record(1)
+	}
+	return err
+}
+
+// instrumentedPolicyStream is an instrumentedStream that wraps a cfs.PolicyStream,
+// forwarding its counters so that they remain visible through the wrapper.
+type instrumentedPolicyStream[V cfs.Value] struct {
+	*instrumentedStream[V]
+	policy cfs.PolicyStream[V]
+}
+
+// Dropped implements cfs.PolicyStream.
+func (s *instrumentedPolicyStream[V]) Dropped() int64 { return s.policy.Dropped() }
+
+// Blocked implements cfs.PolicyStream.
+func (s *instrumentedPolicyStream[V]) Blocked() int64 { return s.policy.Blocked() }
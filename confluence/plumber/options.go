@@ -0,0 +1,24 @@
+package plumber
+
+import "github.com/arya-analytics/x/alamos"
+
+type options struct {
+	experiment alamos.Experiment
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithExperiment instruments every stream routed through the Pipeline, recording
+// metrics under a "plumber" sub-experiment of the provided experiment. See
+// StreamMetrics for more details.
+func WithExperiment(exp alamos.Experiment) Option {
+	return func(o *options) { o.experiment = exp }
+}
@@ -2,6 +2,7 @@ package plumber
 
 import (
 	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/alamos"
 	cfs "github.com/arya-analytics/x/confluence"
 	"github.com/arya-analytics/x/signal"
 	"github.com/cockroachdb/errors"
@@ -52,9 +53,16 @@ func (s *Segment[I, O]) Flow(ctx signal.Context, opts ...cfs.Option) {
 	s.Pipeline.Flow(ctx, opts...)
 }
 
+// Pipeline is a set of addressed sources and sinks that routers wire together. The
+// zero value is an empty, uninstrumented Pipeline ready for use.
 type Pipeline struct {
 	Sources map[address.Address]entry
 	Sinks   map[address.Address]entry
+	// experiment instruments the streams routed through the Pipeline. A nil
+	// experiment disables instrumentation.
+	experiment alamos.Experiment
+	// edges are the streams routed through the Pipeline.
+	edges []EdgeDescription
 }
 
 type entry struct {
@@ -77,10 +85,13 @@ func (p *Pipeline) Flow(ctx signal.Context, opts ...cfs.Option) {
 	}
 }
 
-func New() *Pipeline {
+// New creates a new Pipeline using the provided options.
+func New(opts ...Option) *Pipeline {
+	o := newOptions(opts...)
 	return &Pipeline{
-		Sources: make(map[address.Address]entry),
-		Sinks:   make(map[address.Address]entry),
+		Sources:    make(map[address.Address]entry),
+		Sinks:      make(map[address.Address]entry),
+		experiment: alamos.Sub(o.experiment, "plumber"),
 	}
 }
 
@@ -90,6 +101,9 @@ func SetSource[V cfs.Value](
 	source cfs.Source[V],
 	opts ...cfs.Option,
 ) {
+	if p.Sources == nil {
+		p.Sources = make(map[address.Address]entry)
+	}
 	p.Sources[addr] = entry{flow: source, options: opts}
 }
 
@@ -109,6 +123,9 @@ func SetSink[V cfs.Value](
 	sink cfs.Sink[V],
 	opts ...cfs.Option,
 ) {
+	if p.Sinks == nil {
+		p.Sinks = make(map[address.Address]entry)
+	}
 	p.Sinks[addr] = entry{flow: sink, options: opts}
 }
 
@@ -32,10 +32,13 @@ type UnaryRouter[V cfs.Value] struct {
 	SourceTarget address.Address
 	SinkTarget   address.Address
 	Capacity     int
+	// Policy is the overflow policy applied to the routed stream. Defaults to
+	// cfs.OverflowBlock.
+	Policy cfs.Policy
 }
 
 func (u UnaryRouter[V]) Route(p *Pipeline) error {
-	return route(p, u.SourceTarget, u.SinkTarget, cfs.NewStream[V](u.Capacity))
+	return route(p, u.SourceTarget, u.SinkTarget, newStream[V](u.Capacity, u.Policy))
 }
 
 func (u UnaryRouter[V]) PreRoute(p *Pipeline) func() error {
@@ -55,7 +58,10 @@ type MultiRouter[V cfs.Value] struct {
 	SourceTargets []address.Address
 	SinkTargets   []address.Address
 	Capacity      int
-	Stitch        Stitch
+	// Policy is the overflow policy applied to each routed stream. Defaults to
+	// cfs.OverflowBlock.
+	Policy cfs.Policy
+	Stitch Stitch
 }
 
 func (m MultiRouter[V]) Route(p *Pipeline) error {
@@ -81,7 +87,7 @@ func (m MultiRouter[V]) sinkTargets() []address.Address { return m.SinkTargets }
 func (m MultiRouter[V]) capacity() int { return m.Capacity }
 
 func (m *MultiRouter[V]) linear(p *Pipeline) error {
-	stream := cfs.NewStream[V](m.Capacity)
+	stream := newStream[V](m.Capacity, m.Policy)
 	stream.Acquire(int32(len(m.SourceTargets)))
 	return m.iterAddresses(func(from address.Address, to address.Address) error {
 		return route(p, from, to, stream)
@@ -90,13 +96,18 @@ func (m *MultiRouter[V]) linear(p *Pipeline) error {
 
 func (m MultiRouter[V]) weave(p *Pipeline) error {
 	return m.iterAddresses(func(from, to address.Address) error {
-		return UnaryRouter[V]{from, to, m.Capacity}.Route(p)
+		return UnaryRouter[V]{
+			SourceTarget: from,
+			SinkTarget:   to,
+			Capacity:     m.Capacity,
+			Policy:       m.Policy,
+		}.Route(p)
 	})
 }
 
 func (m MultiRouter[V]) convergent(p *Pipeline) error {
 	return m.iterSinks(func(to address.Address) error {
-		stream := cfs.NewStream[V](m.Capacity)
+		stream := newStream[V](m.Capacity, m.Policy)
 		stream.Acquire(int32(len(m.SourceTargets)))
 		return m.iterSources(func(from address.Address) error {
 			return route(p, from, to, stream)
@@ -133,6 +144,15 @@ func (m MultiRouter[V]) iterSinks(f func(to address.Address) error) error {
 	return nil
 }
 
+// newStream opens a stream that applies the provided policy. Streams with the zero
+// policy are plain streams, as they behave identically.
+func newStream[V cfs.Value](capacity int, policy cfs.Policy) cfs.Stream[V] {
+	if policy == (cfs.Policy{}) {
+		return cfs.NewStream[V](capacity)
+	}
+	return cfs.NewPolicyStream[V](capacity, policy)
+}
+
 func route[V cfs.Value](p *Pipeline, sourceTarget, sinkTarget address.Address, stream cfs.Stream[V]) error {
 	source, err := GetSource[V](p, sourceTarget)
 	if err != nil {
@@ -142,6 +162,14 @@ func route[V cfs.Value](p *Pipeline, sourceTarget, sinkTarget address.Address, s
 	if err != nil {
 		return err
 	}
+	p.edges = append(p.edges, EdgeDescription{
+		Source:   sourceTarget,
+		Sink:     sinkTarget,
+		Capacity: cap(stream.Outlet()),
+	})
+	if p.experiment != nil {
+		stream = instrument[V](p.experiment, sourceTarget, sinkTarget, stream)
+	}
 	stream.SetInletAddress(sinkTarget)
 	source.OutTo(stream)
 	stream.SetOutletAddress(sourceTarget)
@@ -37,6 +37,38 @@ var _ = Describe("Router", func() {
 			source.Out.Inlet() <- 1
 			Expect(sink.In.Outlet()).To(Receive(Equal(1)))
 		})
+		It("Should apply the overflow policy to the routed stream", func() {
+			source := &confluence.Emitter[int]{}
+			sink := &confluence.UnarySink[int]{}
+			plumber.SetSource[int](p, "source", source)
+			plumber.SetSink[int](p, "sink", sink)
+			router := &plumber.UnaryRouter[int]{
+				SourceTarget: "source",
+				SinkTarget:   "sink",
+				Capacity:     1,
+				Policy:       confluence.Policy{Overflow: confluence.OverflowDropOldest},
+			}
+			Expect(router.Route(p)).To(Succeed())
+			Expect(confluence.Send(source.Out, 1)).To(Succeed())
+			Expect(confluence.Send(source.Out, 2)).To(Succeed())
+			Expect(sink.In.Outlet()).To(Receive(Equal(2)))
+			Expect(source.Out.(confluence.PolicyStream[int]).Dropped()).To(Equal(int64(1)))
+		})
+		It("Should route through a zero value Pipeline", func() {
+			p := &plumber.Pipeline{}
+			source := &confluence.Emitter[int]{}
+			sink := &confluence.UnarySink[int]{}
+			plumber.SetSource[int](p, "source", source)
+			plumber.SetSink[int](p, "sink", sink)
+			router := &plumber.UnaryRouter[int]{
+				SourceTarget: "source",
+				SinkTarget:   "sink",
+				Capacity:     1,
+			}
+			Expect(router.Route(p)).To(Succeed())
+			source.Out.Inlet() <- 1
+			Expect(sink.In.Outlet()).To(Receive(Equal(1)))
+		})
 	})
 
 	Describe("MultiRouter", func() {
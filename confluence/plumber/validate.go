@@ -0,0 +1,176 @@
+package plumber
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/cockroachdb/errors"
+	"sort"
+	"strings"
+)
+
+var (
+	// MissingOutlet is returned when a source has no streams routed from it.
+	MissingOutlet = errors.New("[plumber] - source has no outlets")
+	// MissingInlet is returned when a sink has no streams routed to it.
+	MissingInlet = errors.New("[plumber] - sink has no inlets")
+	// DanglingRoute is returned when a route references an address that doesn't
+	// exist in the pipeline.
+	DanglingRoute = errors.New("[plumber] - route references an unknown address")
+	// Cycle is returned when the streams routed through a pipeline form a cycle.
+	Cycle = errors.New("[plumber] - pipeline contains a cycle")
+)
+
+// ValidationError is returned by Pipeline.Validate, and aggregates every problem found
+// in the pipeline. Use the standard library's errors.Is to check whether the
+// ValidationError contains a particular error i.e. errors.Is(err, MissingOutlet).
+type ValidationError struct {
+	Errors []error
+}
+
+// Error implements error.
+func (v ValidationError) Error() string {
+	msgs := make([]string, len(v.Errors))
+	for i, err := range v.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("[plumber] - invalid pipeline: %s", strings.Join(msgs, "; "))
+}
+
+// Is returns true if any of the errors in the ValidationError match target.
+func (v ValidationError) Is(target error) bool {
+	for _, err := range v.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that every source in the Pipeline has at least one outlet, every
+// sink has at least one inlet, no routes reference unknown addresses, and that the
+// routed streams don't form a cycle. Only streams routed using a Router are
+// considered. Returns a ValidationError containing all problems found, or nil if the
+// Pipeline is valid.
+func (p *Pipeline) Validate() error { return p.validate(nil, nil) }
+
+// Validate implements the same checks as Pipeline.Validate, additionally treating
+// the sinks in RouteInletsTo as having an inlet and the sources in RouteOutletsFrom
+// as having an outlet.
+func (s *Segment[I, O]) Validate() error {
+	return s.Pipeline.validate(s.RouteInletsTo, s.RouteOutletsFrom)
+}
+
+func (p *Pipeline) validate(inlets, outlets []address.Address) error {
+	var (
+		errs       []error
+		hasInlet   = make(map[address.Address]bool)
+		hasOutlet  = make(map[address.Address]bool)
+		dependents = make(map[address.Address][]address.Address)
+	)
+	for _, addr := range inlets {
+		if _, ok := p.Sinks[addr]; !ok {
+			errs = append(errs, errors.Wrapf(DanglingRoute, "segment inlet routed to %s", addr))
+		}
+		hasInlet[addr] = true
+	}
+	for _, addr := range outlets {
+		if _, ok := p.Sources[addr]; !ok {
+			errs = append(errs, errors.Wrapf(DanglingRoute, "segment outlet routed from %s", addr))
+		}
+		hasOutlet[addr] = true
+	}
+	for _, e := range p.edges {
+		if _, ok := p.Sources[e.Source]; !ok {
+			errs = append(errs, errors.Wrapf(DanglingRoute, "source %s", e.Source))
+		}
+		if _, ok := p.Sinks[e.Sink]; !ok {
+			errs = append(errs, errors.Wrapf(DanglingRoute, "sink %s", e.Sink))
+		}
+		hasOutlet[e.Source] = true
+		hasInlet[e.Sink] = true
+		dependents[e.Source] = append(dependents[e.Source], e.Sink)
+	}
+	for _, addr := range sortedAddresses(p.Sources) {
+		if !hasOutlet[addr] {
+			errs = append(errs, errors.Wrapf(MissingOutlet, "source %s", addr))
+		}
+	}
+	for _, addr := range sortedAddresses(p.Sinks) {
+		if !hasInlet[addr] {
+			errs = append(errs, errors.Wrapf(MissingInlet, "sink %s", addr))
+		}
+	}
+	if cycle := findCycle(dependents); cycle != nil {
+		errs = append(errs, errors.Wrapf(Cycle, "%s", joinAddresses(cycle)))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return ValidationError{Errors: errs}
+}
+
+// findCycle returns the addresses forming the first cycle found in the graph, or nil
+// if the graph is acyclic.
+func findCycle(graph map[address.Address][]address.Address) []address.Address {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state = make(map[address.Address]int)
+		path  []address.Address
+		visit func(addr address.Address) []address.Address
+	)
+	visit = func(addr address.Address) []address.Address {
+		state[addr] = visiting
+		path = append(path, addr)
+		for _, next := range graph[addr] {
+			switch state[next] {
+			case visiting:
+				for i, a := range path {
+					if a == next {
+						return append(append([]address.Address(nil), path[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[addr] = visited
+		return nil
+	}
+	addrs := make([]address.Address, 0, len(graph))
+	for addr := range graph {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	for _, addr := range addrs {
+		if state[addr] == unvisited {
+			if cycle := visit(addr); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+func sortedAddresses(entries map[address.Address]entry) []address.Address {
+	addrs := make([]address.Address, 0, len(entries))
+	for addr := range entries {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+func joinAddresses(addrs []address.Address) string {
+	strs := make([]string, len(addrs))
+	for i, addr := range addrs {
+		strs[i] = string(addr)
+	}
+	return strings.Join(strs, " -> ")
+}
@@ -0,0 +1,72 @@
+package plumber_test
+
+import (
+	"errors"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/confluence/plumber"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var pipe *plumber.Pipeline
+	BeforeEach(func() {
+		pipe = plumber.New()
+		plumber.SetSource[int](pipe, "source", &confluence.Emitter[int]{})
+		plumber.SetSegment[int, int](pipe, "transform", &confluence.LinearTransform[int, int]{})
+		plumber.SetSink[int](pipe, "sink", &confluence.UnarySink[int]{})
+	})
+	route := func(source, sink address.Address) {
+		Expect(plumber.UnaryRouter[int]{
+			SourceTarget: source,
+			SinkTarget:   sink,
+		}.Route(pipe)).To(Succeed())
+	}
+	It("Should return nil for a valid pipeline", func() {
+		route("source", "transform")
+		route("transform", "sink")
+		Expect(pipe.Validate()).To(Succeed())
+	})
+	It("Should return an error for sources without outlets and sinks without inlets", func() {
+		route("source", "transform")
+		err := pipe.Validate()
+		Expect(errors.Is(err, plumber.MissingOutlet)).To(BeTrue())
+		Expect(errors.Is(err, plumber.MissingInlet)).To(BeTrue())
+		Expect(errors.Is(err, plumber.Cycle)).To(BeFalse())
+		var vErr plumber.ValidationError
+		Expect(errors.As(err, &vErr)).To(BeTrue())
+		Expect(vErr.Errors).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring("source transform"))
+		Expect(err.Error()).To(ContainSubstring("sink sink"))
+	})
+	It("Should return an error for routes that reference unknown addresses", func() {
+		route("source", "transform")
+		route("transform", "sink")
+		delete(pipe.Sinks, "sink")
+		Expect(errors.Is(pipe.Validate(), plumber.DanglingRoute)).To(BeTrue())
+	})
+	It("Should return an error when the pipeline contains a cycle", func() {
+		plumber.SetSegment[int, int](pipe, "loop", &confluence.LinearTransform[int, int]{})
+		route("source", "transform")
+		route("transform", "loop")
+		route("loop", "transform")
+		route("transform", "sink")
+		err := pipe.Validate()
+		Expect(errors.Is(err, plumber.Cycle)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("loop -> transform -> loop"))
+	})
+	Describe("Segment", func() {
+		It("Should treat the segment endpoints as inlets and outlets", func() {
+			pipe = plumber.New()
+			plumber.SetSegment[int, int](pipe, "t1", &confluence.LinearTransform[int, int]{})
+			plumber.SetSegment[int, int](pipe, "t2", &confluence.LinearTransform[int, int]{})
+			route("t1", "t2")
+			seg := &plumber.Segment[int, int]{Pipeline: pipe}
+			Expect(seg.RouteInletTo("t1")).To(Succeed())
+			Expect(errors.Is(seg.Validate(), plumber.MissingOutlet)).To(BeTrue())
+			Expect(seg.RouteOutletFrom("t2")).To(Succeed())
+			Expect(seg.Validate()).To(Succeed())
+		})
+	})
+})
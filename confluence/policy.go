@@ -0,0 +1,135 @@
+package confluence
+
+import (
+	"github.com/cockroachdb/errors"
+	"sync/atomic"
+	"time"
+)
+
+// Overflow is the behavior of a Stream when a value is sent while its buffer is full.
+type Overflow byte
+
+const (
+	// OverflowBlock is the default overflow behavior. The sender blocks until the
+	// value can be added to the buffer.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest discards the value being sent.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest value in the buffer to make room for the
+	// value being sent.
+	OverflowDropOldest
+	// OverflowTimeout blocks the sender for at most Policy.Timeout, after which the
+	// value is discarded and SendTimeout is returned.
+	OverflowTimeout
+)
+
+// SendTimeout is returned by Send when a value can't be added to a Stream with
+// the OverflowTimeout policy before the timeout elapses.
+var SendTimeout = errors.New("[confluence] - timed out sending value to stream")
+
+// Policy defines how a Stream behaves when its buffer is full.
+type Policy struct {
+	// Overflow is the behavior of the Stream when its buffer is full.
+	Overflow Overflow
+	// Timeout is the maximum duration a sender blocks for when using OverflowTimeout.
+	Timeout time.Duration
+}
+
+// InletSender is implemented by Inlet(s) that apply a Policy to the values sent to them.
+type InletSender[V Value] interface {
+	// Send sends the value to the Inlet, applying its Policy.
+	Send(v V) error
+}
+
+// Send sends the value to the provided Inlet. If the Inlet implements InletSender, its
+// Policy is applied to the value. Otherwise, Send blocks until the value is sent.
+// Values sent directly through Inlet.Inlet() bypass any Policy.
+func Send[V Value](inlet Inlet[V], v V) error {
+	if s, ok := inlet.(InletSender[V]); ok {
+		return s.Send(v)
+	}
+	inlet.Inlet() <- v
+	return nil
+}
+
+// PolicyStream is a Stream that applies a Policy to values sent via Send, and keeps
+// track of the sends affected by its Policy.
+type PolicyStream[V Value] interface {
+	Stream[V]
+	InletSender[V]
+	// Dropped returns the number of values discarded by the Stream.
+	Dropped() int64
+	// Blocked returns the number of sends that found the Stream's buffer full.
+	Blocked() int64
+}
+
+// NewPolicyStream opens a new Stream with the given buffer capacity that applies the
+// provided Policy to values sent via Send.
+func NewPolicyStream[V Value](buffer int, policy Policy) PolicyStream[V] {
+	return &policyStream[V]{
+		streamImpl: NewStream[V](buffer).(*streamImpl[V]),
+		policy:     policy,
+	}
+}
+
+type policyStream[V Value] struct {
+	*streamImpl[V]
+	policy           Policy
+	dropped, blocked int64
+}
+
+// Dropped implements PolicyStream.
+func (s *policyStream[V]) Dropped() int64 { return atomic.LoadInt64(&s.dropped) }
+
+// Blocked implements PolicyStream.
+func (s *policyStream[V]) Blocked() int64 { return atomic.LoadInt64(&s.blocked) }
+
+// Send implements InletSender.
+func (s *policyStream[V]) Send(v V) error {
+	select {
+	case s.values <- v:
+		return nil
+	default:
+	}
+	atomic.AddInt64(&s.blocked, 1)
+	switch s.policy.Overflow {
+	case OverflowDropNewest:
+		atomic.AddInt64(&s.dropped, 1)
+		return nil
+	case OverflowDropOldest:
+		return s.dropOldest(v)
+	case OverflowTimeout:
+		t := time.NewTimer(s.policy.Timeout)
+		defer t.Stop()
+		select {
+		case s.values <- v:
+			return nil
+		case <-t.C:
+			atomic.AddInt64(&s.dropped, 1)
+			return SendTimeout
+		}
+	}
+	s.values <- v
+	return nil
+}
+
+func (s *policyStream[V]) dropOldest(v V) error {
+	// An unbuffered stream has no values to discard, so we drop the newest value
+	// instead.
+	if cap(s.values) == 0 {
+		atomic.AddInt64(&s.dropped, 1)
+		return nil
+	}
+	for {
+		select {
+		case s.values <- v:
+			return nil
+		default:
+		}
+		select {
+		case <-s.values:
+			atomic.AddInt64(&s.dropped, 1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package confluence_test
+
+import (
+	"github.com/arya-analytics/x/confluence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Policy", func() {
+	Describe("OverflowBlock", func() {
+		It("Should block until the value can be sent", func() {
+			stream := confluence.NewPolicyStream[int](1, confluence.Policy{})
+			Expect(stream.Send(1)).To(Succeed())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				Expect(stream.Send(2)).To(Succeed())
+				close(done)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			Expect(<-stream.Outlet()).To(Equal(1))
+			Eventually(done).Should(BeClosed())
+			Expect(<-stream.Outlet()).To(Equal(2))
+			Expect(stream.Blocked()).To(Equal(int64(1)))
+			Expect(stream.Dropped()).To(BeZero())
+		})
+	})
+	Describe("OverflowDropNewest", func() {
+		It("Should discard the value being sent when the buffer is full", func() {
+			stream := confluence.NewPolicyStream[int](1, confluence.Policy{
+				Overflow: confluence.OverflowDropNewest,
+			})
+			Expect(stream.Send(1)).To(Succeed())
+			Expect(stream.Send(2)).To(Succeed())
+			Expect(<-stream.Outlet()).To(Equal(1))
+			Expect(stream.Outlet()).ToNot(Receive())
+			Expect(stream.Dropped()).To(Equal(int64(1)))
+		})
+	})
+	Describe("OverflowDropOldest", func() {
+		It("Should discard the oldest value in the buffer when the buffer is full", func() {
+			stream := confluence.NewPolicyStream[int](2, confluence.Policy{
+				Overflow: confluence.OverflowDropOldest,
+			})
+			for i := 1; i <= 4; i++ {
+				Expect(stream.Send(i)).To(Succeed())
+			}
+			Expect(<-stream.Outlet()).To(Equal(3))
+			Expect(<-stream.Outlet()).To(Equal(4))
+			Expect(stream.Dropped()).To(Equal(int64(2)))
+			Expect(stream.Blocked()).To(Equal(int64(2)))
+		})
+	})
+	Describe("OverflowTimeout", func() {
+		It("Should return an error if the value can't be sent before the timeout", func() {
+			stream := confluence.NewPolicyStream[int](1, confluence.Policy{
+				Overflow: confluence.OverflowTimeout,
+				Timeout:  5 * time.Millisecond,
+			})
+			Expect(stream.Send(1)).To(Succeed())
+			Expect(stream.Send(2)).To(MatchError(confluence.SendTimeout))
+			Expect(stream.Dropped()).To(Equal(int64(1)))
+		})
+	})
+	Describe("Send", func() {
+		It("Should send values to inlets that don't apply a policy", func() {
+			stream := confluence.NewStream[int](1)
+			Expect(confluence.Send[int](stream, 1)).To(Succeed())
+			Expect(<-stream.Outlet()).To(Equal(1))
+		})
+	})
+})
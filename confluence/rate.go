@@ -0,0 +1,155 @@
+package confluence
+
+import (
+	"github.com/arya-analytics/x/signal"
+	"time"
+)
+
+// RateLimit is a Segment that limits the rate at which values pass through it using
+// a token bucket. Each value consumes Cost tokens (1 if Cost is nil), and tokens are
+// replenished at Rate per second up to Burst. If there aren't enough tokens for a
+// value, RateLimit blocks until there are.
+type RateLimit[V Value] struct {
+	AbstractLinear[V, V]
+	// Rate is the number of tokens replenished per second. Rate must be positive.
+	Rate float64
+	// Burst is the maximum number of tokens that can accumulate. Defaults to Rate if
+	// zero. A value whose cost exceeds Burst is passed once the bucket is full, and
+	// the deficit is paid off by the following values.
+	Burst float64
+	// Cost returns the number of tokens consumed by a value. This can be used to limit
+	// throughput in bytes per second as opposed to values per second.
+	Cost func(v V) float64
+	// burst is the effective Burst, defaulted to Rate.
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Flow implements the Segment interface.
+func (r *RateLimit[V]) Flow(ctx signal.Context, opts ...Option) {
+	if r.Rate <= 0 {
+		panic("[confluence.RateLimit] - rate must be positive")
+	}
+	o := NewOptions(opts)
+	o.AttachInletCloser(r)
+	r.burst = r.Burst
+	if r.burst <= 0 {
+		r.burst = r.Rate
+	}
+	r.tokens, r.last = r.burst, time.Now()
+	r.GoRange(ctx, r.limit, o.Signal...)
+}
+
+func (r *RateLimit[V]) limit(ctx signal.Context, v V) error {
+	cost := 1.0
+	if r.Cost != nil {
+		cost = r.Cost(v)
+	}
+	required := cost
+	if required > r.burst {
+		required = r.burst
+	}
+	r.refill()
+	if r.tokens < required {
+		wait := time.Duration((required - r.tokens) / r.Rate * float64(time.Second))
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		r.refill()
+	}
+	r.tokens -= cost
+	return Send(r.Out, v)
+}
+
+func (r *RateLimit[V]) refill() {
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.Rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+}
+
+// Throttle is a Segment that forwards at most one value per Interval. If multiple
+// values are received within an Interval, only the latest one is forwarded at the end
+// of the Interval. When the input stream closes, any pending value is forwarded.
+type Throttle[V Value] struct {
+	AbstractLinear[V, V]
+	// Interval is the minimum time between forwarded values. Interval must be
+	// positive.
+	Interval time.Duration
+}
+
+// Flow implements the Segment interface.
+func (t *Throttle[V]) Flow(ctx signal.Context, opts ...Option) {
+	if t.Interval <= 0 {
+		panic("[confluence.Throttle] - interval must be positive")
+	}
+	o := NewOptions(opts)
+	o.AttachInletCloser(t)
+	ctx.Go(t.throttle, o.Signal...)
+}
+
+func (t *Throttle[V]) throttle(ctx signal.Context) error {
+	var (
+		ticker  = time.NewTicker(t.Interval)
+		latest  V
+		pending bool
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-t.In.Outlet():
+			if !ok {
+				if pending {
+					return Send(t.Out, latest)
+				}
+				return nil
+			}
+			latest, pending = v, true
+		case <-ticker.C:
+			if !pending {
+				continue
+			}
+			if err := Send(t.Out, latest); err != nil {
+				return err
+			}
+			pending = false
+		}
+	}
+}
+
+// Sample is a Segment that forwards every Nth value it receives and discards the
+// rest.
+type Sample[V Value] struct {
+	AbstractLinear[V, V]
+	// N is the sampling interval. N must be positive, and an N of 1 forwards every
+	// value.
+	N     int
+	count int
+}
+
+// Flow implements the Segment interface.
+func (s *Sample[V]) Flow(ctx signal.Context, opts ...Option) {
+	if s.N <= 0 {
+		panic("[confluence.Sample] - n must be positive")
+	}
+	o := NewOptions(opts)
+	o.AttachInletCloser(s)
+	s.GoRange(ctx, s.sample, o.Signal...)
+}
+
+func (s *Sample[V]) sample(_ signal.Context, v V) error {
+	s.count++
+	if s.count%s.N != 0 {
+		return nil
+	}
+	return Send(s.Out, v)
+}
@@ -0,0 +1,117 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Rate", func() {
+	var (
+		inlet, outlet confluence.Stream[int]
+		ctx           signal.Context
+		cancel        context.CancelFunc
+	)
+	BeforeEach(func() {
+		inlet = confluence.NewStream[int](10)
+		outlet = confluence.NewStream[int](10)
+		ctx, cancel = signal.WithCancel(context.Background())
+	})
+	AfterEach(func() { cancel() })
+	Describe("RateLimit", func() {
+		It("Should limit the rate at which values are forwarded", func() {
+			limit := &confluence.RateLimit[int]{Rate: 100, Burst: 1}
+			limit.InFrom(inlet)
+			limit.OutTo(outlet)
+			limit.Flow(ctx)
+			start := time.Now()
+			for i := 0; i < 6; i++ {
+				inlet.Inlet() <- i
+			}
+			for i := 0; i < 6; i++ {
+				Expect(<-outlet.Outlet()).To(Equal(i))
+			}
+			Expect(time.Since(start)).To(BeNumerically(">=", 45*time.Millisecond))
+		})
+		It("Should use the cost function to consume tokens", func() {
+			limit := &confluence.RateLimit[int]{
+				Rate:  1000,
+				Burst: 10,
+				Cost:  func(v int) float64 { return float64(v) },
+			}
+			limit.InFrom(inlet)
+			limit.OutTo(outlet)
+			limit.Flow(ctx)
+			start := time.Now()
+			inlet.Inlet() <- 10
+			inlet.Inlet() <- 30
+			inlet.Inlet() <- 1
+			Expect(<-outlet.Outlet()).To(Equal(10))
+			Expect(<-outlet.Outlet()).To(Equal(30))
+			Expect(<-outlet.Outlet()).To(Equal(1))
+			Expect(time.Since(start)).To(BeNumerically(">=", 25*time.Millisecond))
+		})
+		It("Should panic if the rate is not positive", func() {
+			limit := &confluence.RateLimit[int]{}
+			limit.InFrom(inlet)
+			limit.OutTo(outlet)
+			Expect(func() { limit.Flow(ctx) }).To(Panic())
+		})
+		It("Should not modify the configured burst", func() {
+			limit := &confluence.RateLimit[int]{Rate: 100}
+			limit.InFrom(inlet)
+			limit.OutTo(outlet)
+			limit.Flow(ctx)
+			Expect(limit.Burst).To(BeZero())
+		})
+	})
+	Describe("Throttle", func() {
+		It("Should forward only the latest value in each interval", func() {
+			throttle := &confluence.Throttle[int]{Interval: 20 * time.Millisecond}
+			throttle.InFrom(inlet)
+			throttle.OutTo(outlet)
+			throttle.Flow(ctx, confluence.CloseInletsOnExit())
+			for i := 0; i < 5; i++ {
+				inlet.Inlet() <- i
+			}
+			Eventually(outlet.Outlet()).Should(Receive(Equal(4)))
+			inlet.Inlet() <- 5
+			inlet.Close()
+			Expect(<-outlet.Outlet()).To(Equal(5))
+			_, ok := <-outlet.Outlet()
+			Expect(ok).To(BeFalse())
+		})
+		It("Should panic if the interval is not positive", func() {
+			throttle := &confluence.Throttle[int]{}
+			throttle.InFrom(inlet)
+			throttle.OutTo(outlet)
+			Expect(func() { throttle.Flow(ctx) }).To(Panic())
+		})
+	})
+	Describe("Sample", func() {
+		It("Should forward every Nth value", func() {
+			sample := &confluence.Sample[int]{N: 3}
+			sample.InFrom(inlet)
+			sample.OutTo(outlet)
+			sample.Flow(ctx, confluence.CloseInletsOnExit())
+			for i := 1; i <= 9; i++ {
+				inlet.Inlet() <- i
+			}
+			inlet.Close()
+			var values []int
+			for v := range outlet.Outlet() {
+				values = append(values, v)
+			}
+			Expect(values).To(Equal([]int{3, 6, 9}))
+		})
+		It("Should panic if n is not positive", func() {
+			sample := &confluence.Sample[int]{}
+			sample.InFrom(inlet)
+			sample.OutTo(outlet)
+			Expect(func() { sample.Flow(ctx) }).To(Panic())
+		})
+	})
+})
@@ -27,3 +27,15 @@ func (us *UnarySink[V]) Flow(ctx signal.Context, opts ...Option) {
 func (us *UnarySink[V]) GoRange(ctx signal.Context, f func(signal.Context, V) error, opts ...signal.GoOption) {
 	signal.GoRange(ctx, us.In.Outlet(), f, opts...)
 }
+
+// AbstractMultiSink is a basic implementation of a Sink that can receive values from
+// multiple Outlet(s). It does not implement the Flow method, and should be embedded
+// in a concrete segment.
+type AbstractMultiSink[V Value] struct {
+	In []Outlet[V]
+}
+
+// InFrom implements the Sink interface.
+func (ams *AbstractMultiSink[V]) InFrom(outlets ...Outlet[V]) {
+	ams.In = append(ams.In, outlets...)
+}
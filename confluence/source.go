@@ -3,6 +3,7 @@ package confluence
 import (
 	"github.com/arya-analytics/x/address"
 	"github.com/arya-analytics/x/signal"
+	"github.com/cockroachdb/errors"
 )
 
 // AbstractMultiSource is a basic implementation of a Source that can send values to
@@ -18,10 +19,11 @@ func (ams *AbstractMultiSource[V]) OutTo(inlets ...Inlet[V]) { ams.Out = append(
 
 // SendToEach sends the provided value to each Inlet in the Source.
 func (ams *AbstractMultiSource[V]) SendToEach(ctx signal.Context, v V) error {
+	var err error
 	for _, inlet := range ams.Out {
-		inlet.Inlet() <- v
+		err = errors.CombineErrors(err, Send(inlet, v))
 	}
-	return nil
+	return err
 }
 
 // CloseInlets implements the InletCloser interface.
@@ -75,8 +77,7 @@ func (aas *AbstractAddressableSource[V]) Send(target address.Address, v V) error
 	if !ok {
 		return address.TargetNotFound(target)
 	}
-	inlet.Inlet() <- v
-	return nil
+	return Send(inlet, v)
 }
 
 // CloseInlets closes all Inlet(s) provided to AbstractAddressableSource.OutTo.
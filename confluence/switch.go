@@ -26,12 +26,21 @@ type Switch[V Value] struct {
 func (sw *Switch[V]) Flow(ctx signal.Context, opts ...Option) {
 	fo := NewOptions(opts)
 	fo.AttachInletCloser(sw)
-	sw.GoRange(ctx, sw._switch, fo.Signal...)
+	sw.GoRange(ctx, func(ctx signal.Context, v V) error {
+		return sw._switch(ctx, fo, v)
+	}, fo.Signal...)
 }
 
-func (sw *Switch[V]) _switch(ctx signal.Context, v V) error {
-	target, ok, err := sw.SwitchFunc.ApplySwitch(ctx, v)
-	if !ok || err != nil {
+func (sw *Switch[V]) _switch(ctx signal.Context, fo *Options, v V) error {
+	var (
+		target address.Address
+		ok     bool
+	)
+	applied, err := fo.Guard(ctx, v, func() (err error) {
+		target, ok, err = sw.SwitchFunc.ApplySwitch(ctx, v)
+		return err
+	})
+	if !applied || !ok {
 		return err
 	}
 	return sw.Send(target, v)
@@ -62,14 +71,24 @@ func (bsw *BatchSwitch[I, O]) Flow(ctx signal.Context, opts ...Option) {
 	fo := NewOptions(opts)
 	fo.AttachInletCloser(bsw)
 	bsw.addrMap = make(map[address.Address]O)
-	bsw.GoRange(ctx, bsw._switch, fo.Signal...)
+	bsw.GoRange(ctx, func(ctx signal.Context, v I) error {
+		return bsw._switch(ctx, fo, v)
+	}, fo.Signal...)
 }
 
 func (bsw *BatchSwitch[I, O]) _switch(
 	ctx signal.Context,
+	fo *Options,
 	v I,
 ) error {
-	if err := bsw.BatchSwitchFunc.ApplySwitch(ctx, v, bsw.addrMap); err != nil {
+	applied, err := fo.Guard(ctx, v, func() error {
+		// Clear any partial results left by a failed attempt.
+		for target := range bsw.addrMap {
+			delete(bsw.addrMap, target)
+		}
+		return bsw.BatchSwitchFunc.ApplySwitch(ctx, v, bsw.addrMap)
+	})
+	if !applied {
 		return err
 	}
 	for target, batch := range bsw.addrMap {
@@ -1,12 +1,18 @@
 package transfluence
 
 import (
+	"context"
+	"github.com/arya-analytics/x/address"
 	. "github.com/arya-analytics/x/confluence"
 	"github.com/arya-analytics/x/signal"
 	"github.com/arya-analytics/x/transport"
 	"github.com/cockroachdb/errors"
+	"sync"
 )
 
+// receiverExited is returned to streams received after a receiver exits.
+var receiverExited = errors.New("[transfluence] - receiver exited")
+
 // Receiver wraps transport.StreamReceiver to provide a confluence compatible
 // interface for receiving messages from a network transport.
 type Receiver[M transport.Message] struct {
@@ -34,7 +40,9 @@ func (r *Receiver[M]) receive(ctx signal.Context) error {
 			if rErr != nil {
 				return rErr
 			}
-			r.Out.Inlet() <- msg
+			if err := Send(r.Out, msg); err != nil {
+				return err
+			}
 		}
 	}
 }
@@ -49,10 +57,10 @@ type TransformReceiver[I Value, M transport.Message] struct {
 func (r *TransformReceiver[I, M]) Flow(ctx signal.Context, opts ...Option) {
 	o := NewOptions(opts)
 	o.AttachInletCloser(r)
-	ctx.Go(r.receive, o.Signal...)
+	ctx.Go(func(ctx signal.Context) error { return r.receive(ctx, o) }, o.Signal...)
 }
 
-func (r *TransformReceiver[I, M]) receive(ctx signal.Context) error {
+func (r *TransformReceiver[I, M]) receive(ctx signal.Context, fo *Options) error {
 o:
 	for {
 		select {
@@ -66,14 +74,97 @@ o:
 			if err != nil {
 				return err
 			}
-			tRes, ok, err := r.ApplyTransform(ctx, res)
+			tRes, ok, err := r.Transform(ctx, fo, res)
+			if err != nil {
+				return err
+			}
 			if !ok {
 				continue o
 			}
-			if err != nil {
+			if err := Send(r.Out, tRes); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// AddressMessage is a message received by a MultiReceiver, tagged with the address
+// of the stream it was received from.
+type AddressMessage[M transport.Message] struct {
+	Address address.Address
+	Msg     M
+}
+
+// MultiReceiver merges the messages received from any number of streams into a
+// single outlet, tagging each message with the address of its stream. Streams are
+// added as they connect by calling Receive, typically from a transport.Stream
+// handler. A stream that closes or fails only affects its own call to Receive, so the
+// other streams keep flowing. Streams received before Flow is called wait for it.
+//
+// MultiReceiver runs until its context is cancelled, after which Receive returns an
+// error for every open stream.
+type MultiReceiver[M transport.Message] struct {
+	AbstractUnarySource[AddressMessage[M]]
+	once     sync.Once
+	messages chan AddressMessage[M]
+	// exited is closed once the receiver exits, after which streams are rejected.
+	exited chan struct{}
+}
+
+func (r *MultiReceiver[M]) init() {
+	r.once.Do(func() {
+		r.messages = make(chan AddressMessage[M])
+		r.exited = make(chan struct{})
+	})
+}
+
+// Flow implements Flow.
+func (r *MultiReceiver[M]) Flow(ctx signal.Context, opts ...Option) {
+	r.init()
+	fo := NewOptions(opts)
+	fo.AttachInletCloser(r)
+	ctx.Go(r.merge, fo.Signal...)
+}
+
+func (r *MultiReceiver[M]) merge(ctx signal.Context) error {
+	defer close(r.exited)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-r.messages:
+			if err := Send(r.Out, msg); err != nil {
 				return err
 			}
-			r.Out.Inlet() <- tRes
+		}
+	}
+}
+
+// Receive receives messages from the stream until it closes, tagging them with the
+// provided address. Since transport.StreamServer doesn't expose the address of its
+// client, the caller is responsible for identifying the stream. Receive returns nil
+// if the stream closes with transport.EOF, and the error the stream failed with
+// otherwise. Receive can be called concurrently for any number of streams.
+func (r *MultiReceiver[M]) Receive(
+	ctx context.Context,
+	addr address.Address,
+	receiver transport.StreamReceiver[M],
+) error {
+	r.init()
+	for {
+		msg, err := receiver.Receive()
+		if errors.Is(err, transport.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.exited:
+			return receiverExited
+		case r.messages <- AddressMessage[M]{Address: addr, Msg: msg}:
 		}
 	}
 }
@@ -2,13 +2,16 @@ package transfluence_test
 
 import (
 	"context"
+	"github.com/arya-analytics/x/address"
 	"github.com/arya-analytics/x/confluence"
 	"github.com/arya-analytics/x/confluence/transfluence"
 	"github.com/arya-analytics/x/signal"
 	"github.com/arya-analytics/x/transport"
 	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"time"
 )
 
 var _ = Describe("Receiver", func() {
@@ -102,5 +105,118 @@ var _ = Describe("Receiver", func() {
 			_, ok := <-receiverStream.Outlet()
 			Expect(ok).To(BeFalse())
 		})
+		It("Should stop the context when the transform returns an error", func() {
+			errs := make(chan error, 1)
+			stream.Handle(func(ctx context.Context, server transport.StreamServer[int, int]) error {
+				sCtx, cancel := signal.WithCancel(ctx)
+				defer cancel()
+				receiver := &transfluence.TransformReceiver[int, int]{}
+				receiver.Receiver = server
+				receiver.OutTo(confluence.NewStream[int](1))
+				receiver.ApplyTransform = func(ctx signal.Context, v int) (int, bool, error) {
+					return v, true, errors.New("boom")
+				}
+				receiver.Flow(sCtx)
+				err := sCtx.Wait()
+				errs <- err
+				return err
+			})
+			client, err := stream.Stream(context.TODO(), "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Send(1)).To(Succeed())
+			Eventually(errs).Should(Receive(MatchError("boom")))
+		})
+	})
+	Describe("MultiReceiver", func() {
+		var (
+			receiver *transfluence.MultiReceiver[int]
+			outlet   confluence.Stream[transfluence.AddressMessage[int]]
+			errs     chan error
+			sCtx     signal.Context
+			cancel   context.CancelFunc
+		)
+		BeforeEach(func() {
+			receiver = &transfluence.MultiReceiver[int]{}
+			outlet = confluence.NewStream[transfluence.AddressMessage[int]](10)
+			receiver.OutTo(outlet)
+			errs = make(chan error, 10)
+			// Clients identify themselves by sending their index as the first message.
+			stream.Handle(func(ctx context.Context, server transport.StreamServer[int, int]) error {
+				i, err := server.Receive()
+				if err != nil {
+					return err
+				}
+				err = receiver.Receive(ctx, address.Newf("client:%d", i), server)
+				errs <- err
+				return err
+			})
+			sCtx, cancel = signal.WithCancel(context.TODO())
+			receiver.Flow(sCtx, confluence.CloseInletsOnExit())
+		})
+		AfterEach(func() { cancel() })
+		It("Should merge messages from many streams and tag them with their address", func() {
+			var clients []transport.StreamClient[int, int]
+			for i := 0; i < 3; i++ {
+				client, err := stream.Stream(context.TODO(), "localhost:0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(client.Send(i)).To(Succeed())
+				clients = append(clients, client)
+			}
+			for i, client := range clients {
+				Expect(client.Send(i * 10)).To(Succeed())
+			}
+			received := make(map[address.Address]int)
+			for i := 0; i < 3; i++ {
+				msg := <-outlet.Outlet()
+				received[msg.Address] = msg.Msg
+			}
+			Expect(received).To(Equal(map[address.Address]int{
+				"client:0": 0,
+				"client:1": 10,
+				"client:2": 20,
+			}))
+			By("Continuing to receive from other streams when one closes")
+			Expect(clients[0].CloseSend()).To(Succeed())
+			Eventually(errs).Should(Receive(BeNil()))
+			Expect(clients[1].Send(11)).To(Succeed())
+			Eventually(outlet.Outlet()).Should(Receive(Equal(transfluence.AddressMessage[int]{
+				Address: "client:1",
+				Msg:     11,
+			})))
+		})
+		It("Should continue to receive from other streams when one fails", func() {
+			failCtx, failCancel := context.WithCancel(context.TODO())
+			failing, err := stream.Stream(failCtx, "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failing.Send(0)).To(Succeed())
+			Expect(failing.Send(7)).To(Succeed())
+			Eventually(outlet.Outlet()).Should(Receive(Equal(transfluence.AddressMessage[int]{
+				Address: "client:0",
+				Msg:     7,
+			})))
+			healthy, err := stream.Stream(context.TODO(), "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(healthy.Send(1)).To(Succeed())
+			failCancel()
+			Eventually(errs).Should(Receive(MatchError(context.Canceled)))
+			Expect(healthy.Send(5)).To(Succeed())
+			Eventually(outlet.Outlet()).Should(Receive(Equal(transfluence.AddressMessage[int]{
+				Address: "client:1",
+				Msg:     5,
+			})))
+			Expect(healthy.CloseSend()).To(Succeed())
+		})
+		It("Should reject streams once the receiver exits", func() {
+			client, err := stream.Stream(context.TODO(), "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Send(0)).To(Succeed())
+			cancel()
+			Expect(sCtx.Wait()).To(MatchError(context.Canceled))
+			_, ok := <-outlet.Outlet()
+			Expect(ok).To(BeFalse())
+			Expect(client.Send(1)).To(Succeed())
+			Eventually(errs).Should(Receive(HaveOccurred()))
+			Consistently(outlet.Outlet(), 5*time.Millisecond).ShouldNot(Receive())
+		})
 	})
 })
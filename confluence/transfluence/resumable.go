@@ -0,0 +1,380 @@
+package transfluence
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	. "github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"sync"
+	"time"
+)
+
+// Frame is a message sent by a ResumableSender, tagged with its sequence number.
+// Sequence numbers start at 1.
+type Frame[M transport.Message] struct {
+	Seq uint64
+	Msg M
+}
+
+// Ack is sent by a ResumableReceiver to acknowledge every frame up to and including
+// Seq. When a stream is opened, the receiver immediately sends an Ack for the last
+// frame it delivered, so the sender knows where to resume from.
+type Ack struct {
+	Seq uint64
+}
+
+// ReconnectConfig configures how a ResumableSender re-opens a broken stream.
+type ReconnectConfig struct {
+	// MaxRetries is the maximum number of consecutive failed attempts to re-open the
+	// stream before the sender gives up and returns the last error. A MaxRetries of
+	// zero retries until the context is cancelled.
+	MaxRetries int
+	// Interval is the time to wait before the first retry. Defaults to 50
+	// milliseconds.
+	Interval time.Duration
+	// Scale is the factor the interval is multiplied by after each failed attempt. A
+	// Scale less than 1 is treated as 1.
+	Scale float64
+	// MaxInterval caps the interval between attempts. A MaxInterval of zero means no
+	// cap.
+	MaxInterval time.Duration
+}
+
+const defaultReconnectInterval = 50 * time.Millisecond
+
+// backoff returns the interval to wait before the provided retry (starting at 0).
+func (r ReconnectConfig) backoff(retry int) time.Duration {
+	interval := float64(r.Interval)
+	if interval <= 0 {
+		interval = float64(defaultReconnectInterval)
+	}
+	for i := 0; i < retry && r.Scale > 1; i++ {
+		interval *= r.Scale
+		if r.MaxInterval > 0 && interval > float64(r.MaxInterval) {
+			break
+		}
+	}
+	if r.MaxInterval > 0 && interval > float64(r.MaxInterval) {
+		interval = float64(r.MaxInterval)
+	}
+	return time.Duration(interval)
+}
+
+// ResumableSender sends messages to a ResumableReceiver over a stream to Target,
+// re-opening the stream with backoff if it breaks. If Buffer is greater than zero,
+// the sender keeps up to Buffer unacknowledged messages, and resends the ones the
+// receiver hasn't seen after reconnecting, so no messages are lost. Once Buffer
+// messages are unacknowledged, the sender stops reading from its inlet until
+// acknowledgements arrive. If Buffer is zero, messages in flight when the stream
+// breaks are lost.
+//
+// When its inlet closes, the sender closes the stream and waits for the receiver to
+// acknowledge the close before exiting.
+type ResumableSender[M transport.Message] struct {
+	Transport transport.Stream[Frame[M], Ack]
+	Target    address.Address
+	Reconnect ReconnectConfig
+	Buffer    int
+	UnarySink[M]
+}
+
+// Flow implements Flow.
+func (s *ResumableSender[M]) Flow(ctx signal.Context, opts ...Option) {
+	ctx.Go(s.send, NewOptions(opts).Signal...)
+}
+
+func (s *ResumableSender[M]) send(ctx signal.Context) error {
+	var (
+		seq     uint64
+		buf     []Frame[M]
+		closing bool
+	)
+	c, buf, err := s.connect(ctx, buf)
+	if err != nil {
+		return err
+	}
+	defer func() { c.close() }()
+	reconnect := func() error {
+		c.close()
+		next, nextBuf, err := s.connect(ctx, buf)
+		buf = nextBuf
+		if err != nil {
+			return err
+		}
+		c = next
+		if closing {
+			return c.stream.CloseSend()
+		}
+		return nil
+	}
+	for {
+		var in <-chan M
+		if !closing && (s.Buffer <= 0 || len(buf) < s.Buffer) {
+			in = s.In.Outlet()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.acked:
+			buf = trimFrames(buf, c.ack())
+		case <-c.closed:
+			if closing && errors.Is(c.err, transport.EOF) {
+				return nil
+			}
+			if err := reconnect(); err != nil {
+				return err
+			}
+		case msg, ok := <-in:
+			if !ok {
+				closing = true
+				if err := c.stream.CloseSend(); err != nil {
+					return err
+				}
+				continue
+			}
+			seq++
+			f := Frame[M]{Seq: seq, Msg: msg}
+			if s.Buffer > 0 {
+				buf = append(buf, f)
+			}
+			if c.stream.Send(f) != nil {
+				if err := reconnect(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// connect opens a stream to the target, retrying with backoff, and resends the
+// buffered frames the receiver hasn't acknowledged.
+func (s *ResumableSender[M]) connect(
+	ctx context.Context,
+	buf []Frame[M],
+) (*resumableConn[M], []Frame[M], error) {
+	for retry := 0; ; retry++ {
+		c, err := s.open(ctx)
+		if err == nil {
+			buf = trimFrames(buf, c.ack())
+			for _, f := range buf {
+				if err = c.stream.Send(f); err != nil {
+					c.close()
+					break
+				}
+			}
+			if err == nil {
+				return c, buf, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, buf, ctx.Err()
+		}
+		if s.Reconnect.MaxRetries > 0 && retry >= s.Reconnect.MaxRetries {
+			return nil, buf, err
+		}
+		t := time.NewTimer(s.Reconnect.backoff(retry))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, buf, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// open opens a stream to the target and waits for the receiver's first Ack.
+func (s *ResumableSender[M]) open(ctx context.Context) (*resumableConn[M], error) {
+	sCtx, cancel := context.WithCancel(ctx)
+	stream, err := s.Transport.Stream(sCtx, s.Target)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	hello, err := stream.Receive()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c := &resumableConn[M]{
+		stream: stream,
+		cancel: cancel,
+		last:   hello.Seq,
+		acked:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	go c.receive()
+	return c, nil
+}
+
+// resumableConn is a single stream opened by a ResumableSender.
+type resumableConn[M transport.Message] struct {
+	stream transport.StreamClient[Frame[M], Ack]
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	last   uint64
+	// acked is notified when a new Ack is received. Acks are coalesced so that the
+	// receiving goroutine never blocks the receiver's acknowledgements.
+	acked chan struct{}
+	// closed is closed when the stream closes, after which err holds the error it
+	// closed with.
+	closed chan struct{}
+	err    error
+}
+
+func (c *resumableConn[M]) receive() {
+	defer close(c.closed)
+	for {
+		a, err := c.stream.Receive()
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.mu.Lock()
+		if a.Seq > c.last {
+			c.last = a.Seq
+		}
+		c.mu.Unlock()
+		select {
+		case c.acked <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *resumableConn[M]) ack() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+func (c *resumableConn[M]) close() { c.cancel() }
+
+// trimFrames removes the frames acknowledged by seq.
+func trimFrames[M transport.Message](buf []Frame[M], seq uint64) []Frame[M] {
+	i := 0
+	for i < len(buf) && buf[i].Seq <= seq {
+		i++
+	}
+	return buf[i:]
+}
+
+// ResumableReceiver receives messages from a ResumableSender and writes them to its
+// outlet in order, exactly once, across any number of reconnects. To receive
+// streams, pass Handle to the transport's Handle method. Frames received before Flow
+// is called wait for it. The receiver assumes the transport delivers the frames of a
+// single stream in order.
+//
+// The receiver exits once the sender closes a stream. A stream that breaks for any
+// other reason is abandoned, and the sender is expected to reconnect.
+type ResumableReceiver[M transport.Message] struct {
+	AbstractUnarySource[M]
+	once       sync.Once
+	deliveries chan resumableDelivery[M]
+	// exited is closed once the receiver exits, after which streams are rejected.
+	exited chan struct{}
+}
+
+// resumableDelivery is passed from a stream to the receiver's goroutine. A nil frame
+// requests the last delivered sequence number, and eof reports that the sender
+// closed the stream.
+type resumableDelivery[M transport.Message] struct {
+	frame *Frame[M]
+	eof   bool
+	acked chan uint64
+}
+
+func (r *ResumableReceiver[M]) init() {
+	r.once.Do(func() {
+		r.deliveries = make(chan resumableDelivery[M])
+		r.exited = make(chan struct{})
+	})
+}
+
+// Flow implements Flow.
+func (r *ResumableReceiver[M]) Flow(ctx signal.Context, opts ...Option) {
+	r.init()
+	fo := NewOptions(opts)
+	fo.AttachInletCloser(r)
+	ctx.Go(r.receive, fo.Signal...)
+}
+
+func (r *ResumableReceiver[M]) receive(ctx signal.Context) error {
+	defer close(r.exited)
+	var last uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d := <-r.deliveries:
+			if d.eof {
+				d.acked <- last
+				return nil
+			}
+			if d.frame != nil && d.frame.Seq > last {
+				if err := Send(r.Out, d.frame.Msg); err != nil {
+					return err
+				}
+				last = d.frame.Seq
+			}
+			d.acked <- last
+		}
+	}
+}
+
+// Handle handles a stream opened by a ResumableSender. Handle satisfies the
+// signature of transport.Stream.Handle.
+func (r *ResumableReceiver[M]) Handle(
+	ctx context.Context,
+	srv transport.StreamServer[Frame[M], Ack],
+) error {
+	r.init()
+	last, err := r.deliver(ctx, resumableDelivery[M]{})
+	if err != nil {
+		return err
+	}
+	if err := srv.Send(Ack{Seq: last}); err != nil {
+		return err
+	}
+	for {
+		f, rErr := srv.Receive()
+		if errors.Is(rErr, transport.EOF) {
+			_, err := r.deliver(ctx, resumableDelivery[M]{eof: true})
+			return err
+		}
+		if rErr != nil {
+			return rErr
+		}
+		if last, err = r.deliver(ctx, resumableDelivery[M]{frame: &f}); err != nil {
+			return err
+		}
+		if err := srv.Send(Ack{Seq: last}); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *ResumableReceiver[M]) deliver(ctx context.Context, d resumableDelivery[M]) (uint64, error) {
+	d.acked = make(chan uint64, 1)
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-r.exited:
+		return 0, receiverExited
+	case r.deliveries <- d:
+	}
+	select {
+	case last := <-d.acked:
+		return last, nil
+	case <-r.exited:
+		// The receiver acknowledges the close before exiting, so we need to check
+		// for the acknowledgement before giving up.
+		select {
+		case last := <-d.acked:
+			return last, nil
+		default:
+			return 0, receiverExited
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package transfluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/confluence/transfluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/transport"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync/atomic"
+	"time"
+)
+
+var broken = errors.New("broken")
+
+// flakyServer breaks the stream after receiving a number of frames, or after
+// sending a number of acknowledgements.
+type flakyServer struct {
+	transport.StreamServer[transfluence.Frame[int], transfluence.Ack]
+	receives, sends int
+}
+
+func (f *flakyServer) Receive() (transfluence.Frame[int], error) {
+	if f.receives == 0 {
+		return transfluence.Frame[int]{}, broken
+	}
+	f.receives--
+	return f.StreamServer.Receive()
+}
+
+func (f *flakyServer) Send(a transfluence.Ack) error {
+	if f.sends == 0 {
+		return broken
+	}
+	f.sends--
+	return f.StreamServer.Send(a)
+}
+
+var _ = Describe("Resumable", func() {
+	var (
+		net        *tmock.Network[transfluence.Frame[int], transfluence.Ack]
+		client     *tmock.Stream[transfluence.Frame[int], transfluence.Ack]
+		server     *tmock.Stream[transfluence.Frame[int], transfluence.Ack]
+		sender     *transfluence.ResumableSender[int]
+		receiver   *transfluence.ResumableReceiver[int]
+		input      confluence.Stream[int]
+		output     confluence.Stream[int]
+		ctx        signal.Context
+		cancel     context.CancelFunc
+		streamsCnt int32
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[transfluence.Frame[int], transfluence.Ack]()
+		client = net.RouteStream("", 10)
+		server = net.RouteStream("", 10)
+		streamsCnt = 0
+		input = confluence.NewStream[int](0)
+		output = confluence.NewStream[int](20)
+		sender = &transfluence.ResumableSender[int]{
+			Transport: client,
+			Target:    server.Address,
+			Reconnect: transfluence.ReconnectConfig{Interval: time.Millisecond},
+			Buffer:    20,
+		}
+		sender.InFrom(input)
+		receiver = &transfluence.ResumableReceiver[int]{}
+		receiver.OutTo(output)
+		ctx, cancel = signal.WithTimeout(context.TODO(), 2*time.Second)
+	})
+	AfterEach(func() { cancel() })
+	run := func() []int {
+		sender.Flow(ctx)
+		receiver.Flow(ctx, confluence.CloseInletsOnExit())
+		for i := 1; i <= 10; i++ {
+			input.Inlet() <- i
+		}
+		input.Close()
+		Expect(ctx.Wait()).To(Succeed())
+		var values []int
+		for v := range output.Outlet() {
+			values = append(values, v)
+		}
+		return values
+	}
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	It("Should deliver every message over a healthy stream", func() {
+		server.Handle(receiver.Handle)
+		Expect(run()).To(Equal(expected))
+		Expect(net.StreamHistory()).To(HaveLen(1))
+	})
+	It("Should resend messages lost when the stream breaks", func() {
+		server.Handle(func(
+			ctx context.Context,
+			srv transport.StreamServer[transfluence.Frame[int], transfluence.Ack],
+		) error {
+			if atomic.AddInt32(&streamsCnt, 1) == 1 {
+				return receiver.Handle(ctx, &flakyServer{StreamServer: srv, receives: 3, sends: -1})
+			}
+			return receiver.Handle(ctx, srv)
+		})
+		Expect(run()).To(Equal(expected))
+		Expect(len(net.StreamHistory())).To(BeNumerically(">=", 2))
+	})
+	It("Should not deliver messages twice when acknowledgements are lost", func() {
+		server.Handle(func(
+			ctx context.Context,
+			srv transport.StreamServer[transfluence.Frame[int], transfluence.Ack],
+		) error {
+			if atomic.AddInt32(&streamsCnt, 1) == 1 {
+				// The first ack is the handshake, so the stream breaks after delivering
+				// two messages without acknowledging the second.
+				return receiver.Handle(ctx, &flakyServer{StreamServer: srv, receives: -1, sends: 2})
+			}
+			return receiver.Handle(ctx, srv)
+		})
+		Expect(run()).To(Equal(expected))
+		Expect(len(net.StreamHistory())).To(BeNumerically(">=", 2))
+	})
+	It("Should give up after the maximum number of retries", func() {
+		server.Handle(receiver.Handle)
+		net.InjectError(server.Address, broken)
+		sender.Reconnect.MaxRetries = 2
+		sender.Flow(ctx)
+		Expect(ctx.Wait()).To(MatchError(broken))
+	})
+})
@@ -58,10 +58,11 @@ type TransformSender[I Value, M transport.Message] struct {
 
 // Flow implements the Flow interface.
 func (s *TransformSender[I, M]) Flow(ctx signal.Context, opts ...Option) {
-	ctx.Go(s.send, NewOptions(opts).Signal...)
+	fo := NewOptions(opts)
+	ctx.Go(func(ctx signal.Context) error { return s.send(ctx, fo) }, fo.Signal...)
 }
 
-func (s *TransformSender[I, M]) send(ctx signal.Context) error {
+func (s *TransformSender[I, M]) send(ctx signal.Context, fo *Options) error {
 	var err error
 	defer func() {
 		err = errors.CombineErrors(s.Sender.CloseSend(), err)
@@ -76,11 +77,14 @@ o:
 			if !ok {
 				break o
 			}
-			tRes, ok, tErr := s.TransformFunc.ApplyTransform(ctx, res)
+			tRes, ok, tErr := s.Transform(ctx, fo, res)
 			if tErr != nil {
 				err = tErr
 				break o
 			}
+			if !ok {
+				continue o
+			}
 			if sErr := s.Sender.Send(tRes); sErr != nil {
 				err = sErr
 				break o
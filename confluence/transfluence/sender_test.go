@@ -102,6 +102,29 @@ var _ = Describe("Sender", func() {
 				senderStream.Inlet() <- 1
 				Expect(sCtx.Wait()).To(MatchError("error"))
 			})
+			It("Should apply the error policy to transform errors", func() {
+				sCtx, cancel := signal.WithCancel(context.TODO())
+				defer cancel()
+				client, err := streamTransport.Stream(sCtx, "localhost:0")
+				Expect(err).ToNot(HaveOccurred())
+				sender := &transfluence.TransformSender[int, int]{}
+				sender.Sender = client
+				sender.ApplyTransform = func(ctx signal.Context, v int) (int, bool, error) {
+					if v%2 != 0 {
+						return 0, false, errors.New("odd")
+					}
+					return v * 2, true, nil
+				}
+				sender.InFrom(senderStream)
+				deadLetter := confluence.NewStream[confluence.ValueError](1)
+				sender.Flow(sCtx, confluence.DeadLetterErrors(deadLetter))
+				senderStream.Inlet() <- 1
+				senderStream.Inlet() <- 2
+				Expect(<-receiverStream.Outlet()).To(Equal(4))
+				vErr := <-deadLetter.Outlet()
+				Expect(vErr.Value).To(Equal(1))
+				Expect(vErr.Err).To(MatchError("odd"))
+			})
 		})
 	})
 	Context("Multiple Streams", func() {
@@ -0,0 +1,237 @@
+package confluence
+
+import (
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/telem"
+	"sort"
+)
+
+// WindowConfig is the configuration shared by all windowed aggregation segments.
+//
+// Windows are assigned using the timestamp of each value (as opposed to the time the
+// value was received). A window is closed and reduced once a value is received whose
+// timestamp is at least AllowedLateness past the end of the window. Values that
+// arrive after their window has been closed are discarded. When the input stream
+// closes, all open windows are reduced in order.
+type WindowConfig[I, O Value] struct {
+	// Timestamp extracts the timestamp of a value.
+	Timestamp func(v I) telem.TimeStamp
+	// Reduce is called with the values in a window once it closes. If it returns
+	// false, no result is emitted for the window. If it returns an error, the segment
+	// exits and returns a fatal error to the context.
+	Reduce func(ctx signal.Context, window telem.TimeRange, values []I) (O, bool, error)
+	// AllowedLateness is the amount of time a window is kept open after its end to
+	// accept out of order values.
+	AllowedLateness telem.TimeSpan
+}
+
+// TumblingWindow is a Segment that groups values into fixed size, non-overlapping
+// windows of Span.
+type TumblingWindow[I, O Value] struct {
+	AbstractLinear[I, O]
+	WindowConfig[I, O]
+	// Span is the size of each window.
+	Span telem.TimeSpan
+}
+
+// Flow implements the Segment interface.
+func (t *TumblingWindow[I, O]) Flow(ctx signal.Context, opts ...Option) {
+	if t.Span <= 0 {
+		panic("[confluence.TumblingWindow] - span must be positive")
+	}
+	w := &windower[I, O]{
+		AbstractLinear: &t.AbstractLinear,
+		WindowConfig:   t.WindowConfig,
+		assign: func(ts telem.TimeStamp) []telem.TimeRange {
+			return []telem.TimeRange{alignedRange(ts, t.Span, t.Span)}
+		},
+	}
+	w.flow(ctx, t, opts...)
+}
+
+// SlidingWindow is a Segment that groups values into fixed size windows of Span that
+// start every Slide. A value can belong to multiple windows.
+type SlidingWindow[I, O Value] struct {
+	AbstractLinear[I, O]
+	WindowConfig[I, O]
+	// Span is the size of each window.
+	Span telem.TimeSpan
+	// Slide is the interval between the start of consecutive windows.
+	Slide telem.TimeSpan
+}
+
+// Flow implements the Segment interface.
+func (s *SlidingWindow[I, O]) Flow(ctx signal.Context, opts ...Option) {
+	if s.Span <= 0 || s.Slide <= 0 {
+		panic("[confluence.SlidingWindow] - span and slide must be positive")
+	}
+	w := &windower[I, O]{
+		AbstractLinear: &s.AbstractLinear,
+		WindowConfig:   s.WindowConfig,
+		assign: func(ts telem.TimeStamp) (windows []telem.TimeRange) {
+			for rng := alignedRange(ts, s.Slide, s.Span); rng.ContainsStamp(ts); {
+				windows = append(windows, rng)
+				rng.Start, rng.End = rng.Start.Sub(s.Slide), rng.End.Sub(s.Slide)
+			}
+			return windows
+		},
+	}
+	w.flow(ctx, s, opts...)
+}
+
+// SessionWindow is a Segment that groups values into windows of activity separated
+// by at least Gap. Each value opens a window of Gap, and overlapping windows are
+// merged.
+type SessionWindow[I, O Value] struct {
+	AbstractLinear[I, O]
+	WindowConfig[I, O]
+	// Gap is the minimum amount of inactivity that separates two sessions.
+	Gap telem.TimeSpan
+}
+
+// Flow implements the Segment interface.
+func (s *SessionWindow[I, O]) Flow(ctx signal.Context, opts ...Option) {
+	if s.Gap <= 0 {
+		panic("[confluence.SessionWindow] - gap must be positive")
+	}
+	w := &windower[I, O]{
+		AbstractLinear: &s.AbstractLinear,
+		WindowConfig:   s.WindowConfig,
+		assign: func(ts telem.TimeStamp) []telem.TimeRange {
+			return []telem.TimeRange{ts.SpanRange(s.Gap)}
+		},
+		merge: true,
+	}
+	w.flow(ctx, s, opts...)
+}
+
+// alignedRange returns the range of span whose start is the last multiple of align
+// before or at ts.
+func alignedRange(ts telem.TimeStamp, align, span telem.TimeSpan) telem.TimeRange {
+	offset := ((int64(ts) % int64(align)) + int64(align)) % int64(align)
+	return ts.Sub(telem.TimeSpan(offset)).SpanRange(span)
+}
+
+type pane[I Value] struct {
+	rng    telem.TimeRange
+	values []I
+}
+
+// windower implements the windowing logic shared by all windowed segments.
+type windower[I, O Value] struct {
+	*AbstractLinear[I, O]
+	WindowConfig[I, O]
+	// assign returns the windows a value with the provided timestamp belongs to.
+	assign func(ts telem.TimeStamp) []telem.TimeRange
+	// merge sets whether overlapping windows should be merged.
+	merge bool
+	panes []*pane[I]
+	// watermark is the time before which all windows have been closed.
+	watermark telem.TimeStamp
+	// seen is set once the first value has been received.
+	seen bool
+}
+
+func (w *windower[I, O]) flow(ctx signal.Context, closer InletCloser, opts ...Option) {
+	o := NewOptions(opts)
+	o.AttachInletCloser(closer)
+	ctx.Go(func(ctx signal.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case v, ok := <-w.In.Outlet():
+				if !ok {
+					return w.fire(ctx, func(telem.TimeRange) bool { return true })
+				}
+				if err := w.add(ctx, v); err != nil {
+					return err
+				}
+			}
+		}
+	}, o.Signal...)
+}
+
+func (w *windower[I, O]) add(ctx signal.Context, v I) error {
+	ts := w.Timestamp(v)
+	for _, rng := range w.assign(ts) {
+		if w.seen && rng.End.BeforeEq(w.watermark) {
+			continue
+		}
+		if w.merge {
+			w.mergePane(rng, v)
+		} else {
+			w.insertPane(rng, v)
+		}
+	}
+	if wm := ts.Sub(w.AllowedLateness); !w.seen || wm.After(w.watermark) {
+		w.watermark, w.seen = wm, true
+	}
+	return w.fire(ctx, func(rng telem.TimeRange) bool { return rng.End.BeforeEq(w.watermark) })
+}
+
+func (w *windower[I, O]) insertPane(rng telem.TimeRange, v I) {
+	for _, p := range w.panes {
+		if p.rng == rng {
+			p.values = append(p.values, v)
+			return
+		}
+	}
+	w.panes = append(w.panes, &pane[I]{rng: rng, values: []I{v}})
+}
+
+func (w *windower[I, O]) mergePane(rng telem.TimeRange, v I) {
+	merged := &pane[I]{rng: rng}
+	remaining := w.panes[:0]
+	for _, p := range w.panes {
+		// Windows that only touch are separated by exactly Gap, so they belong to
+		// different sessions.
+		if !p.rng.OverlapsWith(merged.rng) {
+			remaining = append(remaining, p)
+			continue
+		}
+		if p.rng.Start.Before(merged.rng.Start) {
+			merged.rng.Start = p.rng.Start
+		}
+		if p.rng.End.After(merged.rng.End) {
+			merged.rng.End = p.rng.End
+		}
+		merged.values = append(merged.values, p.values...)
+	}
+	merged.values = append(merged.values, v)
+	w.panes = append(remaining, merged)
+}
+
+// fire reduces and emits the panes whose range matches the provided filter in order
+// of their end time.
+func (w *windower[I, O]) fire(ctx signal.Context, filter func(telem.TimeRange) bool) error {
+	var ready []*pane[I]
+	remaining := w.panes[:0]
+	for _, p := range w.panes {
+		if filter(p.rng) {
+			ready = append(ready, p)
+		} else {
+			remaining = append(remaining, p)
+		}
+	}
+	w.panes = remaining
+	sort.Slice(ready, func(i, j int) bool {
+		if ready[i].rng.End == ready[j].rng.End {
+			return ready[i].rng.Start.Before(ready[j].rng.Start)
+		}
+		return ready[i].rng.End.Before(ready[j].rng.End)
+	})
+	for _, p := range ready {
+		o, ok, err := w.Reduce(ctx, p.rng, p.values)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := Send(w.Out, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
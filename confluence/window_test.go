@@ -0,0 +1,190 @@
+package confluence_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/confluence"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/telem"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type sample struct {
+	ts    telem.TimeStamp
+	value int
+}
+
+type windowResult struct {
+	window telem.TimeRange
+	sum    int
+}
+
+func sampleConfig(lateness telem.TimeSpan) confluence.WindowConfig[sample, windowResult] {
+	return confluence.WindowConfig[sample, windowResult]{
+		Timestamp: func(s sample) telem.TimeStamp { return s.ts },
+		Reduce: func(
+			_ signal.Context,
+			window telem.TimeRange,
+			values []sample,
+		) (windowResult, bool, error) {
+			r := windowResult{window: window}
+			for _, v := range values {
+				r.sum += v.value
+			}
+			return r, true, nil
+		},
+		AllowedLateness: lateness,
+	}
+}
+
+func runWindow(
+	seg confluence.Segment[sample, windowResult],
+	samples ...sample,
+) []windowResult {
+	inlet := confluence.NewStream[sample](len(samples))
+	outlet := confluence.NewStream[windowResult](10)
+	seg.InFrom(inlet)
+	seg.OutTo(outlet)
+	ctx, cancel := signal.WithCancel(context.Background())
+	defer cancel()
+	seg.Flow(ctx, confluence.CloseInletsOnExit())
+	for _, s := range samples {
+		inlet.Inlet() <- s
+	}
+	inlet.Close()
+	var results []windowResult
+	for r := range outlet.Outlet() {
+		results = append(results, r)
+	}
+	Expect(ctx.Wait()).To(Succeed())
+	return results
+}
+
+func rng(start, end telem.TimeStamp) telem.TimeRange {
+	return telem.TimeRange{Start: start, End: end}
+}
+
+var _ = Describe("Window", func() {
+	Describe("TumblingWindow", func() {
+		It("Should group values into fixed, non-overlapping windows", func() {
+			w := &confluence.TumblingWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(0),
+				Span:         10,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 5, value: 2},
+				sample{ts: 12, value: 3},
+				sample{ts: 25, value: 4},
+			)).To(Equal([]windowResult{
+				{window: rng(0, 10), sum: 3},
+				{window: rng(10, 20), sum: 3},
+				{window: rng(20, 30), sum: 4},
+			}))
+		})
+		It("Should accept late values within the allowed lateness", func() {
+			w := &confluence.TumblingWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(5),
+				Span:         10,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 12, value: 2},
+				sample{ts: 8, value: 3},
+				sample{ts: 16, value: 4},
+				sample{ts: 9, value: 5},
+			)).To(Equal([]windowResult{
+				{window: rng(0, 10), sum: 4},
+				{window: rng(10, 20), sum: 6},
+			}))
+		})
+		It("Should panic if the span is not positive", func() {
+			w := &confluence.TumblingWindow[sample, windowResult]{WindowConfig: sampleConfig(0)}
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			Expect(func() { w.Flow(ctx) }).To(Panic())
+		})
+	})
+	Describe("SlidingWindow", func() {
+		It("Should assign values to every overlapping window", func() {
+			w := &confluence.SlidingWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(0),
+				Span:         10,
+				Slide:        5,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 7, value: 2},
+				sample{ts: 12, value: 4},
+			)).To(Equal([]windowResult{
+				{window: rng(-5, 5), sum: 1},
+				{window: rng(0, 10), sum: 3},
+				{window: rng(5, 15), sum: 6},
+				{window: rng(10, 20), sum: 4},
+			}))
+		})
+		It("Should panic if the span or slide is not positive", func() {
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			zeroSpan := &confluence.SlidingWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(0),
+				Slide:        5,
+			}
+			Expect(func() { zeroSpan.Flow(ctx) }).To(Panic())
+			zeroSlide := &confluence.SlidingWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(0),
+				Span:         10,
+			}
+			Expect(func() { zeroSlide.Flow(ctx) }).To(Panic())
+		})
+	})
+	Describe("SessionWindow", func() {
+		It("Should group values separated by less than the gap into a session", func() {
+			w := &confluence.SessionWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(0),
+				Gap:          5,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 4, value: 2},
+				sample{ts: 20, value: 3},
+				sample{ts: 22, value: 4},
+			)).To(Equal([]windowResult{
+				{window: rng(1, 9), sum: 3},
+				{window: rng(20, 27), sum: 7},
+			}))
+		})
+		It("Should merge sessions bridged by an out of order value", func() {
+			w := &confluence.SessionWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(20),
+				Gap:          5,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 8, value: 2},
+				sample{ts: 4, value: 3},
+			)).To(Equal([]windowResult{{window: rng(1, 13), sum: 6}}))
+		})
+		It("Should separate values exactly the gap apart into different sessions", func() {
+			w := &confluence.SessionWindow[sample, windowResult]{
+				WindowConfig: sampleConfig(20),
+				Gap:          5,
+			}
+			Expect(runWindow(w,
+				sample{ts: 1, value: 1},
+				sample{ts: 11, value: 2},
+				sample{ts: 6, value: 4},
+			)).To(Equal([]windowResult{
+				{window: rng(1, 6), sum: 1},
+				{window: rng(6, 11), sum: 4},
+				{window: rng(11, 16), sum: 2},
+			}))
+		})
+		It("Should panic if the gap is not positive", func() {
+			w := &confluence.SessionWindow[sample, windowResult]{WindowConfig: sampleConfig(0)}
+			ctx, cancel := signal.WithCancel(context.Background())
+			defer cancel()
+			Expect(func() { w.Flow(ctx) }).To(Panic())
+		})
+	})
+})
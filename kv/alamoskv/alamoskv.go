@@ -0,0 +1,180 @@
+// Package alamoskv implements a kv.DB decorator that records the count, latency, and
+// value sizes of operations as alamos metrics. It can also log a sample of slow
+// operations to a zap logger. To instrument a DB, call Wrap.
+package alamoskv
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/alamos"
+	"github.com/arya-analytics/x/kv"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are the metrics recorded by an instrumented DB.
+type Metrics struct {
+	// Get tracks the number of gets and their average latency.
+	Get alamos.Duration
+	// Set tracks the number of sets and their average latency.
+	Set alamos.Duration
+	// Delete tracks the number of deletes and their average latency.
+	Delete alamos.Duration
+	// Commit tracks the number of batch commits and their average latency.
+	Commit alamos.Duration
+	// IterSeek tracks the number of iterator seeks (First, Last, SeekGE, SeekLT) and
+	// their average latency.
+	IterSeek alamos.Duration
+	// GetSize tracks the size of values returned by gets.
+	GetSize alamos.Metric[int]
+	// SetSize tracks the size of values written by sets, including those written
+	// to a batch.
+	SetSize alamos.Metric[int]
+}
+
+func newMetrics(exp alamos.Experiment) Metrics {
+	subExp := alamos.Sub(exp, "kv")
+	return Metrics{
+		Get:      alamos.NewGaugeDuration(subExp, alamos.Debug, "get"),
+		Set:      alamos.NewGaugeDuration(subExp, alamos.Debug, "set"),
+		Delete:   alamos.NewGaugeDuration(subExp, alamos.Debug, "delete"),
+		Commit:   alamos.NewGaugeDuration(subExp, alamos.Debug, "batch.commit"),
+		IterSeek: alamos.NewGaugeDuration(subExp, alamos.Debug, "iter.seek"),
+		GetSize:  alamos.NewGauge[int](subExp, alamos.Debug, "get.size"),
+		SetSize:  alamos.NewGauge[int](subExp, alamos.Debug, "set.size"),
+	}
+}
+
+// DB is an instrumented kv.DB. Set and Delete operations on a batch are not
+// timed individually, as they don't touch the underlying store until the batch is
+// committed.
+type DB struct {
+	kv.DB
+	options
+	metrics Metrics
+	// slow is the number of slow operations encountered. It's used to sample
+	// which of them get logged.
+	slow int64
+}
+
+// Wrap instruments the provided kv.DB using the provided options.
+func Wrap(db kv.DB, opts ...Option) *DB {
+	o := newOptions(opts...)
+	return &DB{DB: db, options: *o, metrics: newMetrics(o.experiment)}
+}
+
+// Metrics returns the metrics recorded by the DB.
+func (db *DB) Metrics() Metrics { return db.metrics }
+
+// Get implements kv.DB.
+func (db *DB) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	return db.get(db.DB, key, opts...)
+}
+
+// Set implements kv.DB.
+func (db *DB) Set(key []byte, value []byte, opts ...interface{}) error {
+	start := time.Now()
+	err := db.DB.Set(key, value, opts...)
+	db.record("set", db.metrics.Set, start, key)
+	db.metrics.SetSize.Record(len(value))
+	return err
+}
+
+// Delete implements kv.DB.
+func (db *DB) Delete(key []byte) error {
+	start := time.Now()
+	err := db.DB.Delete(key)
+	db.record("delete", db.metrics.Delete, start, key)
+	return err
+}
+
+// NewIterator implements kv.DB.
+func (db *DB) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return &iterator{Iterator: db.DB.NewIterator(opts), db: db}
+}
+
+// NewBatch implements kv.DB.
+func (db *DB) NewBatch() kv.Batch { return &batch{Batch: db.DB.NewBatch(), db: db} }
+
+// String implements kv.DB.
+func (db *DB) String() string { return fmt.Sprintf("alamoskv{%s}", db.DB) }
+
+func (db *DB) get(r kv.Reader, key []byte, opts ...interface{}) ([]byte, error) {
+	start := time.Now()
+	v, err := r.Get(key, opts...)
+	db.record("get", db.metrics.Get, start, key)
+	if err == nil {
+		db.metrics.GetSize.Record(len(v))
+	}
+	return v, err
+}
+
+func (db *DB) record(op string, m alamos.Duration, start time.Time, key []byte) {
+	dur := time.Since(start)
+	m.Record(dur)
+	if db.slowThreshold <= 0 || dur < db.slowThreshold {
+		return
+	}
+	if n := atomic.AddInt64(&db.slow, 1); (n-1)%int64(db.sampleEvery) != 0 {
+		return
+	}
+	db.logger.Warn("slow kv operation",
+		zap.String("op", op),
+		zap.ByteString("key", key),
+		zap.Duration("duration", dur),
+		zap.Stringer("db", db.DB),
+	)
+}
+
+type batch struct {
+	kv.Batch
+	db *DB
+}
+
+// Get implements kv.Batch.
+func (b *batch) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	return b.db.get(b.Batch, key, opts...)
+}
+
+// Set implements kv.Batch.
+func (b *batch) Set(key []byte, value []byte, opts ...interface{}) error {
+	b.db.metrics.SetSize.Record(len(value))
+	return b.Batch.Set(key, value, opts...)
+}
+
+// NewIterator implements kv.Batch.
+func (b *batch) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return &iterator{Iterator: b.Batch.NewIterator(opts), db: b.db}
+}
+
+// Commit implements kv.Batch.
+func (b *batch) Commit(opts ...interface{}) error {
+	start := time.Now()
+	err := b.Batch.Commit(opts...)
+	b.db.record("batch.commit", b.db.metrics.Commit, start, nil)
+	return err
+}
+
+type iterator struct {
+	kv.Iterator
+	db *DB
+}
+
+// First implements kv.Iterator.
+func (i *iterator) First() bool { return i.seek(nil, func([]byte) bool { return i.Iterator.First() }) }
+
+// Last implements kv.Iterator.
+func (i *iterator) Last() bool { return i.seek(nil, func([]byte) bool { return i.Iterator.Last() }) }
+
+// SeekGE implements kv.Iterator.
+func (i *iterator) SeekGE(key []byte) bool { return i.seek(key, i.Iterator.SeekGE) }
+
+// SeekLT implements kv.Iterator.
+func (i *iterator) SeekLT(key []byte) bool { return i.seek(key, i.Iterator.SeekLT) }
+
+func (i *iterator) seek(key []byte, f func([]byte) bool) bool {
+	start := time.Now()
+	ok := f(key)
+	i.db.record("iter.seek", i.db.metrics.IterSeek, start, key)
+	return ok
+}
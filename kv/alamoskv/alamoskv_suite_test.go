@@ -0,0 +1,13 @@
+package alamoskv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAlamoskv(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Alamoskv Suite")
+}
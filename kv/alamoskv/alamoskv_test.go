@@ -0,0 +1,93 @@
+package alamoskv_test
+
+import (
+	"github.com/arya-analytics/x/alamos"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/kv/alamoskv"
+	"github.com/arya-analytics/x/kv/memkv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"time"
+)
+
+var _ = Describe("Alamoskv", func() {
+	var (
+		db *alamoskv.DB
+	)
+	BeforeEach(func() {
+		db = alamoskv.Wrap(memkv.New(), alamoskv.WithExperiment(alamos.New("test")))
+	})
+	AfterEach(func() { Expect(db.Close()).To(Succeed()) })
+	Describe("Get and Set", func() {
+		It("Should record the count, latency, and value size", func() {
+			Expect(db.Set([]byte("key"), []byte("value"))).To(Succeed())
+			Expect(db.Get([]byte("key"))).To(Equal([]byte("value")))
+			m := db.Metrics()
+			Expect(m.Set.Count()).To(Equal(1))
+			Expect(m.Get.Count()).To(Equal(1))
+			Expect(m.Get.Values()[0]).ToNot(BeZero())
+			Expect(m.SetSize.Values()[1]).To(Equal(5))
+			Expect(m.GetSize.Values()[1]).To(Equal(5))
+		})
+		It("Should not record the size of values that weren't found", func() {
+			_, err := db.Get([]byte("missing"))
+			Expect(err).To(MatchError(kv.NotFound))
+			Expect(db.Metrics().Get.Count()).To(Equal(1))
+			Expect(db.Metrics().GetSize.Count()).To(Equal(0))
+		})
+	})
+	Describe("Delete", func() {
+		It("Should record the number of deletes", func() {
+			Expect(db.Delete([]byte("key"))).To(Succeed())
+			Expect(db.Metrics().Delete.Count()).To(Equal(1))
+		})
+	})
+	Describe("Batch", func() {
+		It("Should record commits and the size of values written", func() {
+			b := db.NewBatch()
+			Expect(b.Set([]byte("key"), []byte("value"))).To(Succeed())
+			Expect(b.Commit()).To(Succeed())
+			Expect(db.Metrics().Commit.Count()).To(Equal(1))
+			Expect(db.Metrics().Set.Count()).To(Equal(0))
+			Expect(db.Metrics().SetSize.Count()).To(Equal(1))
+		})
+	})
+	Describe("Iterator", func() {
+		It("Should record iterator seeks", func() {
+			Expect(db.Set([]byte("key"), []byte("value"))).To(Succeed())
+			iter := db.NewIterator(kv.PrefixIter([]byte("k")))
+			Expect(iter.First()).To(BeTrue())
+			Expect(iter.SeekGE([]byte("key"))).To(BeTrue())
+			Expect(iter.Next()).To(BeFalse())
+			Expect(iter.Close()).To(Succeed())
+			Expect(db.Metrics().IterSeek.Count()).To(Equal(2))
+		})
+	})
+	Describe("Slow Operations", func() {
+		It("Should log a sample of slow operations", func() {
+			core, logs := observer.New(zap.WarnLevel)
+			sDB := alamoskv.Wrap(
+				memkv.New(),
+				alamoskv.WithLogger(zap.New(core)),
+				alamoskv.WithSlowThreshold(1*time.Nanosecond),
+				alamoskv.WithSampling(2),
+			)
+			for i := 0; i < 4; i++ {
+				Expect(sDB.Set([]byte("key"), []byte("value"))).To(Succeed())
+			}
+			Expect(logs.Len()).To(Equal(2))
+			Expect(logs.All()[0].ContextMap()["op"]).To(Equal("set"))
+			Expect(sDB.Close()).To(Succeed())
+		})
+	})
+	Describe("Empty Experiment", func() {
+		It("Should operate normally without an experiment", func() {
+			eDB := alamoskv.Wrap(memkv.New())
+			Expect(eDB.Set([]byte("key"), []byte("value"))).To(Succeed())
+			Expect(eDB.Get([]byte("key"))).To(Equal([]byte("value")))
+			Expect(eDB.Close()).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package alamoskv
+
+import (
+	"github.com/arya-analytics/x/alamos"
+	"go.uber.org/zap"
+	"time"
+)
+
+type options struct {
+	experiment    alamos.Experiment
+	logger        *zap.Logger
+	slowThreshold time.Duration
+	sampleEvery   int
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	mergeDefaultOptions(o)
+	return o
+}
+
+func mergeDefaultOptions(o *options) {
+	if o.logger == nil {
+		o.logger = zap.NewNop()
+	}
+	if o.sampleEvery <= 0 {
+		o.sampleEvery = 1
+	}
+}
+
+// WithExperiment sets the experiment that the DB records its Metrics under.
+func WithExperiment(exp alamos.Experiment) Option {
+	return func(o *options) { o.experiment = exp }
+}
+
+// WithLogger sets the logger that slow operations are logged to.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithSlowThreshold sets the duration above which an operation is considered slow
+// and is logged. If the threshold is zero (the default), slow operations are not
+// logged.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(o *options) { o.slowThreshold = threshold }
+}
+
+// WithSampling logs only one in every n slow operations. By default, every slow
+// operation is logged.
+func WithSampling(n int) Option {
+	return func(o *options) { o.sampleEvery = n }
+}
@@ -0,0 +1,86 @@
+// Package compresskv implements a kv.DB decorator that transparently compresses
+// values before writing them to an underlying DB, and decompresses them when they're
+// read back. Keys are left untouched, so prefix and range iteration behave the same as
+// they do on the wrapped DB. Values written to the DB before it was wrapped remain
+// readable. For details on the storage format, see the compress package.
+package compresskv
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/compress"
+	"github.com/arya-analytics/x/kv"
+)
+
+type db struct {
+	kv.DB
+	c *compress.Compressor
+}
+
+// Wrap wraps the provided kv.DB so that its values are compressed using a
+// compress.Compressor configured with the provided options.
+func Wrap(kvDB kv.DB, opts ...compress.Option) kv.DB {
+	return &db{DB: kvDB, c: compress.New(opts...)}
+}
+
+// Get implements kv.DB.
+func (d *db) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	return get(d.DB, d.c, key, opts...)
+}
+
+// Set implements kv.DB.
+func (d *db) Set(key []byte, value []byte, opts ...interface{}) error {
+	return d.DB.Set(key, d.c.Compress(value), opts...)
+}
+
+// NewIterator implements kv.DB.
+func (d *db) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return &iterator{Iterator: d.DB.NewIterator(opts), c: d.c}
+}
+
+// NewBatch implements kv.DB.
+func (d *db) NewBatch() kv.Batch { return &batch{Batch: d.DB.NewBatch(), c: d.c} }
+
+// String implements kv.DB.
+func (d *db) String() string { return fmt.Sprintf("compresskv{%s}", d.DB) }
+
+type batch struct {
+	kv.Batch
+	c *compress.Compressor
+}
+
+// Get implements kv.Batch.
+func (b *batch) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	return get(b.Batch, b.c, key, opts...)
+}
+
+// Set implements kv.Batch.
+func (b *batch) Set(key []byte, value []byte, opts ...interface{}) error {
+	return b.Batch.Set(key, b.c.Compress(value), opts...)
+}
+
+// NewIterator implements kv.Batch.
+func (b *batch) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return &iterator{Iterator: b.Batch.NewIterator(opts), c: b.c}
+}
+
+type iterator struct {
+	kv.Iterator
+	c *compress.Compressor
+}
+
+// Value implements kv.Iterator.
+func (i *iterator) Value() []byte {
+	v := i.Iterator.Value()
+	if v == nil {
+		return nil
+	}
+	return i.c.Decompress(v)
+}
+
+func get(r kv.Reader, c *compress.Compressor, key []byte, opts ...interface{}) ([]byte, error) {
+	v, err := r.Get(key, opts...)
+	if err != nil {
+		return v, err
+	}
+	return c.Decompress(v), nil
+}
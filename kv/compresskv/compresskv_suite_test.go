@@ -0,0 +1,13 @@
+package compresskv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCompresskv(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Compresskv Suite")
+}
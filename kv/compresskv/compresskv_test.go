@@ -0,0 +1,76 @@
+package compresskv_test
+
+import (
+	"bytes"
+	"github.com/arya-analytics/x/compress"
+	"github.com/arya-analytics/x/gorp"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/kv/compresskv"
+	"github.com/arya-analytics/x/kv/memkv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+type entry struct {
+	ID   int
+	Data string
+}
+
+func (e entry) GorpKey() int { return e.ID }
+
+func (e entry) SetOptions() []interface{} { return nil }
+
+var _ = Describe("Compresskv", func() {
+	var (
+		base  kv.DB
+		db    kv.DB
+		large = bytes.Repeat([]byte("compress me "), 100)
+	)
+	BeforeEach(func() {
+		base = memkv.New()
+		db = compresskv.Wrap(base, compress.WithCodec(compress.Snappy))
+	})
+	AfterEach(func() { Expect(db.Close()).To(Succeed()) })
+	It("Should compress values in the underlying DB", func() {
+		Expect(db.Set([]byte("key"), large)).To(Succeed())
+		raw, err := base.Get([]byte("key"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(raw)).To(BeNumerically("<", len(large)))
+		Expect(db.Get([]byte("key"))).To(Equal(large))
+	})
+	It("Should read legacy uncompressed values", func() {
+		Expect(base.Set([]byte("key"), large)).To(Succeed())
+		Expect(db.Get([]byte("key"))).To(Equal(large))
+	})
+	It("Should return kv.NotFound for missing keys", func() {
+		_, err := db.Get([]byte("missing"))
+		Expect(err).To(MatchError(kv.NotFound))
+	})
+	It("Should decompress values when iterating", func() {
+		Expect(db.Set([]byte("key1"), large)).To(Succeed())
+		Expect(db.Set([]byte("key2"), []byte("small"))).To(Succeed())
+		iter := db.NewIterator(kv.PrefixIter([]byte("key")))
+		var values [][]byte
+		for iter.First(); iter.Valid(); iter.Next() {
+			values = append(values, iter.Value())
+		}
+		Expect(iter.Close()).To(Succeed())
+		Expect(values).To(Equal([][]byte{large, []byte("small")}))
+	})
+	It("Should compress values written through a batch", func() {
+		b := db.NewBatch()
+		Expect(b.Set([]byte("key"), large)).To(Succeed())
+		Expect(b.Get([]byte("key"))).To(Equal(large))
+		Expect(b.Commit()).To(Succeed())
+		Expect(db.Get([]byte("key"))).To(Equal(large))
+	})
+	It("Should be usable as the store for a gorp.DB", func() {
+		gDB := gorp.Wrap(db)
+		e := entry{ID: 1, Data: strings.Repeat("gorp", 100)}
+		Expect(gorp.NewCreate[int, entry]().Entry(&e).Exec(gDB)).To(Succeed())
+		var res entry
+		Expect(gorp.NewRetrieve[int, entry]().WhereKeys(1).Entry(&res).Exec(gDB)).To(Succeed())
+		Expect(res).To(Equal(e))
+	})
+})
@@ -4,6 +4,9 @@
 //
 // For a general implementation of DB, see the pebblekv package.
 // For an in-memory implementation of DB, see the memkv package.
+// For a DB decorator that compresses values, see the compresskv package.
+// For a DB decorator that records metrics, see the alamoskv package.
+// For a DB that replicates its contents to a set of peers, see the replicakv package.
 //
 package kv
 
@@ -0,0 +1,121 @@
+package kv
+
+import (
+	"bytes"
+	"github.com/arya-analytics/x/binary"
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// Persisted stores a value of any type under a key in a DB, encoding it using a
+// binary.EncoderDecoder. Persisted caches the value in memory, so reads never hit
+// the DB, and assumes it's the only writer to its key. Persisted is safe for
+// concurrent use, and implements the observe.Observable interface. To open a new
+// Persisted, call OpenPersisted.
+type Persisted[T any] struct {
+	db       DB
+	key      []byte
+	ecd      binary.EncoderDecoder
+	mu       sync.Mutex
+	value    T
+	handlers []func(T)
+}
+
+// OpenPersisted opens the value stored under the given key, decoding it using the
+// provided binary.EncoderDecoder. If ecd is nil, values are encoded using
+// binary.GobEncoderDecoder. If no value is stored under the key, the Persisted starts
+// with the zero value of T.
+func OpenPersisted[T any](db DB, key []byte, ecd binary.EncoderDecoder) (*Persisted[T], error) {
+	if ecd == nil {
+		ecd = &binary.GobEncoderDecoder{}
+	}
+	p := &Persisted[T]{db: db, key: key, ecd: ecd}
+	b, err := db.Get(key)
+	if errors.Is(err, NotFound) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, ecd.Decode(b, &p.value)
+}
+
+// Get returns the current value.
+func (p *Persisted[T]) Get() T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value
+}
+
+// Set persists the value to the DB, and notifies any handlers bound via OnChange.
+func (p *Persisted[T]) Set(value T) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b, err := p.ecd.Encode(value)
+	if err != nil {
+		return err
+	}
+	return p.setLocked(b, value)
+}
+
+// CompareAndSwap persists next only if the encoded form of the current value is equal
+// to the encoded form of old. Returns true if the swap was performed. It's important
+// to note that values are compared using their encoded representation, so
+// CompareAndSwap should only be used with an EncoderDecoder that encodes equal
+// values deterministically.
+func (p *Persisted[T]) CompareAndSwap(old, next T) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ob, err := p.ecd.Encode(old)
+	if err != nil {
+		return false, err
+	}
+	cur, err := p.ecd.Encode(p.value)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(ob, cur) {
+		return false, nil
+	}
+	nb, err := p.ecd.Encode(next)
+	if err != nil {
+		return false, err
+	}
+	return true, p.setLocked(nb, next)
+}
+
+// Update atomically applies f to the current value, and persists the result. If f
+// returns an error, the value is left unchanged.
+func (p *Persisted[T]) Update(f func(T) (T, error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	next, err := f(p.value)
+	if err != nil {
+		return err
+	}
+	b, err := p.ecd.Encode(next)
+	if err != nil {
+		return err
+	}
+	return p.setLocked(b, next)
+}
+
+// OnChange implements the observe.Observable interface. The handler is called with
+// the new value every time it's persisted. Handlers are called synchronously while
+// the Persisted is locked, so they must not call methods on the Persisted.
+func (p *Persisted[T]) OnChange(handler func(T)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *Persisted[T]) setLocked(b []byte, value T) error {
+	if err := p.db.Set(p.key, b); err != nil {
+		return err
+	}
+	p.value = value
+	for _, h := range p.handlers {
+		h(value)
+	}
+	return nil
+}
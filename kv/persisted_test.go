@@ -0,0 +1,119 @@
+package kv_test
+
+import (
+	"github.com/arya-analytics/x/binary"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/kv/memkv"
+	"github.com/arya-analytics/x/observe"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"io"
+)
+
+type persistedConfig struct {
+	Name  string
+	Count int
+}
+
+type persistedFlushLoader struct {
+	Value int64
+}
+
+func (f persistedFlushLoader) Flush(w io.Writer) error { return binary.Write(w, f.Value) }
+
+func (f *persistedFlushLoader) Load(r io.Reader) error { return binary.Read(r, &f.Value) }
+
+var _ = Describe("Persisted", func() {
+	var kve kv.DB
+	BeforeEach(func() { kve = memkv.New() })
+	AfterEach(func() { Expect(kve.Close()).To(Succeed()) })
+	Describe("Set and Get", func() {
+		It("Should start with the zero value when nothing is stored", func() {
+			p, err := kv.OpenPersisted[persistedConfig](kve, []byte("cfg"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Get()).To(Equal(persistedConfig{}))
+		})
+		It("Should load a previously persisted value", func() {
+			p, err := kv.OpenPersisted[persistedConfig](kve, []byte("cfg"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Set(persistedConfig{Name: "a", Count: 1})).To(Succeed())
+			pTwo, err := kv.OpenPersisted[persistedConfig](kve, []byte("cfg"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pTwo.Get()).To(Equal(persistedConfig{Name: "a", Count: 1}))
+		})
+		It("Should use the provided EncoderDecoder", func() {
+			ecd := &binary.GobEncoderDecoder{}
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), ecd)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Set(42)).To(Succeed())
+			var v int64
+			b, err := kve.Get([]byte("n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ecd.Decode(b, &v)).To(Succeed())
+			Expect(v).To(Equal(int64(42)))
+		})
+		It("Should store FlushLoaders using a FlushLoaderEncoderDecoder", func() {
+			ecd := &kv.FlushLoaderEncoderDecoder{}
+			p, err := kv.OpenPersisted[persistedFlushLoader](kve, []byte("fl"), ecd)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Set(persistedFlushLoader{Value: 7})).To(Succeed())
+			Expect(kve.Get([]byte("fl"))).To(Equal([]byte{0, 0, 0, 0, 0, 0, 0, 7}))
+			pTwo, err := kv.OpenPersisted[persistedFlushLoader](kve, []byte("fl"), ecd)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pTwo.Get()).To(Equal(persistedFlushLoader{Value: 7}))
+		})
+		It("Should return an error when the value doesn't implement Flusher", func() {
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), &kv.FlushLoaderEncoderDecoder{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Set(1)).ToNot(Succeed())
+		})
+	})
+	Describe("CompareAndSwap", func() {
+		It("Should swap the value when the old value matches", func() {
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.CompareAndSwap(0, 1)).To(BeTrue())
+			Expect(p.Get()).To(Equal(int64(1)))
+		})
+		It("Should not swap the value when the old value doesn't match", func() {
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Set(5)).To(Succeed())
+			Expect(p.CompareAndSwap(4, 6)).To(BeFalse())
+			Expect(p.Get()).To(Equal(int64(5)))
+		})
+		It("Should compare against the value persisted by Update", func() {
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Update(func(v int64) (int64, error) { return v + 3, nil })).To(Succeed())
+			Expect(p.CompareAndSwap(0, 1)).To(BeFalse())
+			Expect(p.CompareAndSwap(3, 4)).To(BeTrue())
+			Expect(p.Get()).To(Equal(int64(4)))
+		})
+	})
+	Describe("Update", func() {
+		It("Should apply the update function to the current value", func() {
+			p, err := kv.OpenPersisted[persistedConfig](kve, []byte("cfg"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.Update(func(c persistedConfig) (persistedConfig, error) {
+				c.Count++
+				return c, nil
+			})).To(Succeed())
+			Expect(p.Get().Count).To(Equal(1))
+		})
+	})
+	Describe("OnChange", func() {
+		It("Should be observable through the observe package", func() {
+			p, err := kv.OpenPersisted[int64](kve, []byte("n"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			var (
+				o      observe.Observable[int64] = p
+				values []int64
+			)
+			o.OnChange(func(v int64) { values = append(values, v) })
+			Expect(p.Set(1)).To(Succeed())
+			Expect(p.CompareAndSwap(1, 2)).To(BeTrue())
+			Expect(values).To(Equal([]int64{1, 2}))
+		})
+	})
+})
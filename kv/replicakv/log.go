@@ -0,0 +1,88 @@
+package replicakv
+
+import (
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/binary"
+	"github.com/arya-analytics/x/kv"
+	"github.com/cockroachdb/errors"
+)
+
+// The outbound log persists committed batches in the metadata keyspace until every
+// peer has acknowledged them, so replication resumes where it left off when the DB
+// is re-opened. Entries are keyed by a sequence number assigned at commit, and each
+// peer has a cursor holding the sequence number of the last entry it acknowledged.
+
+var (
+	logKeyPrefix    = []byte{metaPrefix, 'l'}
+	cursorKeyPrefix = []byte{metaPrefix, 'c'}
+	batchCodec      = &binary.GobEncoderDecoder{}
+)
+
+// logEntry is a batch in the outbound log.
+type logEntry struct {
+	seq   uint64
+	batch Batch
+}
+
+func logKey(seq uint64) []byte {
+	k := make([]byte, len(logKeyPrefix)+8)
+	copy(k, logKeyPrefix)
+	binary.Encoding().PutUint64(k[len(logKeyPrefix):], seq)
+	return k
+}
+
+func logSeq(key []byte) uint64 { return binary.Encoding().Uint64(key[len(logKeyPrefix):]) }
+
+func cursorKey(addr address.Address) []byte {
+	return append(binary.MakeCopy(cursorKeyPrefix), addr...)
+}
+
+func appendLog(w kv.Writer, e logEntry) error {
+	b, err := batchCodec.Encode(e.batch)
+	if err != nil {
+		return err
+	}
+	return w.Set(logKey(e.seq), b)
+}
+
+// loadLog returns the entries in the log in sequence order.
+func loadLog(r kv.Reader) (entries []logEntry, err error) {
+	iter := r.NewIterator(kv.PrefixIter(logKeyPrefix))
+	for iter.First(); iter.Valid(); iter.Next() {
+		e := logEntry{seq: logSeq(iter.Key())}
+		if err = batchCodec.Decode(iter.Value(), &e.batch); err != nil {
+			return nil, errors.CombineErrors(err, iter.Close())
+		}
+		entries = append(entries, e)
+	}
+	return entries, iter.Close()
+}
+
+// truncateLog removes the entries in the range (from, to].
+func truncateLog(w kv.Writer, from, to uint64) error {
+	for seq := from + 1; seq <= to; seq++ {
+		if err := w.Delete(logKey(seq)); err != nil && !errors.Is(err, kv.NotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCursors returns the cursors of every peer that has acknowledged an entry.
+func loadCursors(r kv.Reader) (map[address.Address]uint64, error) {
+	cursors := make(map[address.Address]uint64)
+	iter := r.NewIterator(kv.PrefixIter(cursorKeyPrefix))
+	for iter.First(); iter.Valid(); iter.Next() {
+		addr := address.Address(iter.Key()[len(cursorKeyPrefix):])
+		cursors[addr] = binary.Encoding().Uint64(iter.Value())
+	}
+	return cursors, iter.Close()
+}
+
+func setCursor(w kv.Writer, addr address.Address, seq uint64) error {
+	b, err := binary.Marshal(seq)
+	if err != nil {
+		return err
+	}
+	return w.Set(cursorKey(addr), b)
+}
@@ -0,0 +1,44 @@
+package replicakv
+
+import (
+	"go.uber.org/zap"
+	"time"
+)
+
+type options struct {
+	logger        *zap.Logger
+	retryInterval time.Duration
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	mergeDefaultOptions(o)
+	return o
+}
+
+const defaultRetryInterval = 100 * time.Millisecond
+
+func mergeDefaultOptions(o *options) {
+	if o.logger == nil {
+		o.logger = zap.NewNop()
+	}
+	if o.retryInterval <= 0 {
+		o.retryInterval = defaultRetryInterval
+	}
+}
+
+// WithLogger sets the logger that replication failures are logged to.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithRetryInterval sets the interval between attempts to reach an unreachable
+// peer.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(o *options) { o.retryInterval = interval }
+}
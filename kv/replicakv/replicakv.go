@@ -0,0 +1,395 @@
+// Package replicakv implements a kv.DB that replicates its contents to a set of peers
+// over a transport.Stream. Every node keeps a full copy of the data in a local engine
+// (typically a pebblekv or memkv DB), and forwards each committed batch to all of its
+// peers. Peers apply batches in the order they were committed.
+//
+// Conflicts between concurrent writes to the same key are resolved using a
+// last-writer-wins policy. Each node maintains a version.Heartbeat that is
+// incremented on every local commit, and is merged with the heartbeat of every
+// batch received from a peer (in the style of a Lamport clock). A committed batch is
+// stamped with the node's heartbeat and address, and an operation is only applied to
+// a key if its version is newer than the one last applied to the key. Ties between
+// equal heartbeats are broken using the address of the node that committed the batch.
+//
+// Replication is asynchronous. Commit returns once the batch has been applied to the
+// local engine, and batches are kept in an outbound log in the engine until they are
+// acknowledged by each peer, so batches committed before a restart are delivered once
+// the DB is re-opened. Unreachable peers are retried indefinitely.
+//
+// Keys beginning with 0xFF are reserved for storing replication metadata, and
+// cannot be written to. Deleted keys leave a small tombstone in the metadata
+// keyspace so that stale writes aren't resurrected. Tombstones are never removed, as
+// there's no way to know when a stale write can no longer arrive.
+//
+// Options passed to Set and Commit are applied to the local engine only, and are
+// not replicated to peers.
+package replicakv
+
+import (
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/binary"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/transport"
+	"github.com/arya-analytics/x/version"
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// ReservedKey is returned when attempting to write a key in the reserved metadata
+// keyspace.
+var ReservedKey = errors.New("[replicakv] - keys beginning with 0xFF are reserved")
+
+// Variant is the type of operation.
+type Variant uint8
+
+const (
+	// VariantSet sets the value of a key.
+	VariantSet Variant = iota + 1
+	// VariantDelete deletes a key.
+	VariantDelete
+)
+
+// Operation is a single key-value operation within a Batch.
+type Operation struct {
+	Variant Variant
+	Key     []byte
+	Value   []byte
+}
+
+// Batch is a committed set of operations exchanged between replicas.
+type Batch struct {
+	// Origin is the address of the node that committed the batch.
+	Origin address.Address
+	// Heartbeat is the heartbeat of the origin node at the time of commit.
+	Heartbeat  version.Heartbeat
+	Operations []Operation
+}
+
+func (b Batch) version() Version { return Version{Heartbeat: b.Heartbeat, Origin: b.Origin} }
+
+// Ack is sent by a replica once it has applied a Batch.
+type Ack struct {
+	// Heartbeat is the heartbeat of the applied batch.
+	Heartbeat version.Heartbeat
+}
+
+// Transport is the transport used to exchange batches between replicas.
+type Transport = transport.Stream[Batch, Ack]
+
+// Config is the configuration for opening a replicated DB.
+type Config struct {
+	// Address is the address of the node. It's used to break ties between concurrent
+	// writes, so it must be unique amongst all replicas.
+	Address address.Address
+	// Peers are the addresses of the nodes to replicate to.
+	Peers []address.Address
+	// Transport is used to receive batches from and forward batches to peers.
+	Transport Transport
+	// Engine is the local store that batches are applied to.
+	Engine kv.DB
+}
+
+// DB is a replicated kv.DB. To open a new DB, call Open.
+type DB struct {
+	Config
+	*options
+	mu struct {
+		sync.Mutex
+		heartbeat version.Heartbeat
+		// seq is the sequence number of the last batch appended to the outbound log.
+		seq uint64
+		// truncated is the sequence number of the last batch removed from the
+		// outbound log.
+		truncated uint64
+	}
+	peers []*peer
+}
+
+// Open opens a replicated DB using the provided configuration, and starts forwarding
+// committed batches to peers under the provided context. The DB's heartbeat is
+// loaded from the engine and restarted, and batches in the outbound log that a peer
+// hasn't acknowledged are queued for delivery to it. To stop replication, cancel
+// the context.
+func Open(ctx signal.Context, cfg Config, opts ...Option) (*DB, error) {
+	db := &DB{Config: cfg, options: newOptions(opts...)}
+	hb, err := loadHeartbeat(cfg.Engine)
+	if err != nil {
+		return nil, err
+	}
+	db.mu.heartbeat = hb.Restart()
+	if err := setHeartbeat(cfg.Engine, db.mu.heartbeat); err != nil {
+		return nil, err
+	}
+	if err := db.loadPeers(); err != nil {
+		return nil, err
+	}
+	cfg.Transport.Handle(db.handle)
+	for _, p := range db.peers {
+		p := p
+		ctx.Go(func(ctx signal.Context) error { return db.replicate(ctx, p) })
+	}
+	return db, nil
+}
+
+// loadPeers queues the batches in the outbound log for each peer that hasn't
+// acknowledged them.
+func (db *DB) loadPeers() error {
+	entries, err := loadLog(db.Engine)
+	if err != nil {
+		return err
+	}
+	cursors, err := loadCursors(db.Engine)
+	if err != nil {
+		return err
+	}
+	// Once every entry is acknowledged, the log is empty and the last sequence number
+	// is only held by the cursors.
+	for _, seq := range cursors {
+		if seq > db.mu.seq {
+			db.mu.seq = seq
+		}
+	}
+	if len(entries) > 0 {
+		db.mu.seq = entries[len(entries)-1].seq
+		db.mu.truncated = entries[0].seq - 1
+	} else {
+		db.mu.truncated = db.mu.seq
+	}
+	for _, addr := range db.Peers {
+		p := newPeer(addr, cursors[addr])
+		for _, e := range entries {
+			if e.seq > p.acked {
+				p.pending = append(p.pending, e)
+			}
+		}
+		db.peers = append(db.peers, p)
+	}
+	return nil
+}
+
+// Heartbeat returns the current heartbeat of the DB.
+func (db *DB) Heartbeat() version.Heartbeat {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.mu.heartbeat
+}
+
+// Get implements kv.DB.
+func (db *DB) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	if reserved(key) {
+		return nil, kv.NotFound
+	}
+	return db.Engine.Get(key, opts...)
+}
+
+// Set implements kv.DB.
+func (db *DB) Set(key []byte, value []byte, opts ...interface{}) error {
+	b := db.NewBatch()
+	if err := b.Set(key, value, opts...); err != nil {
+		return errors.CombineErrors(err, b.Close())
+	}
+	return b.Commit()
+}
+
+// Delete implements kv.DB.
+func (db *DB) Delete(key []byte) error {
+	b := db.NewBatch()
+	if err := b.Delete(key); err != nil {
+		return errors.CombineErrors(err, b.Close())
+	}
+	return b.Commit()
+}
+
+// NewIterator implements kv.DB. The returned iterator never reaches the reserved
+// metadata keyspace.
+func (db *DB) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return db.Engine.NewIterator(clampBounds(opts))
+}
+
+// NewBatch implements kv.DB. When the batch is committed, it's forwarded to all
+// peers.
+func (db *DB) NewBatch() kv.Batch { return &batch{db: db, Batch: db.Engine.NewBatch()} }
+
+// Close implements kv.DB. Close closes the underlying engine, so the context
+// provided to Open should be cancelled beforehand.
+func (db *DB) Close() error { return db.Engine.Close() }
+
+// String implements kv.DB.
+func (db *DB) String() string {
+	return fmt.Sprintf("replicakv{%s} at %s", db.Engine, db.Address)
+}
+
+// commit stamps the operations in the provided engine batch with a new version,
+// commits them, and queues them for replication.
+func (db *DB) commit(eb kv.Batch, ops []Operation, opts ...interface{}) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	hb := db.mu.heartbeat.Increment()
+	b := Batch{Origin: db.Address, Heartbeat: hb, Operations: ops}
+	v := b.version()
+	for _, op := range ops {
+		if err := setVersion(eb, op.Key, v, op.Variant == VariantDelete); err != nil {
+			return errors.CombineErrors(err, eb.Close())
+		}
+	}
+	if err := setHeartbeat(eb, hb); err != nil {
+		return errors.CombineErrors(err, eb.Close())
+	}
+	e := logEntry{seq: db.mu.seq + 1, batch: b}
+	if len(db.peers) > 0 {
+		if err := appendLog(eb, e); err != nil {
+			return errors.CombineErrors(err, eb.Close())
+		}
+	}
+	if err := eb.Commit(opts...); err != nil {
+		return err
+	}
+	db.mu.heartbeat = hb
+	if len(db.peers) > 0 {
+		db.mu.seq = e.seq
+	}
+	for _, p := range db.peers {
+		p.push(e)
+	}
+	return nil
+}
+
+// acknowledge advances the cursor of the peer to the provided entry, and removes
+// the entries acknowledged by every peer from the outbound log.
+func (db *DB) acknowledge(p *peer, seq uint64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	p.acked = seq
+	truncate := seq
+	for _, other := range db.peers {
+		if other.acked < truncate {
+			truncate = other.acked
+		}
+	}
+	eb := db.Engine.NewBatch()
+	if err := setCursor(eb, p.addr, seq); err != nil {
+		return errors.CombineErrors(err, eb.Close())
+	}
+	if truncate > db.mu.truncated {
+		if err := truncateLog(eb, db.mu.truncated, truncate); err != nil {
+			return errors.CombineErrors(err, eb.Close())
+		}
+	}
+	if err := eb.Commit(); err != nil {
+		return err
+	}
+	if truncate > db.mu.truncated {
+		db.mu.truncated = truncate
+	}
+	return nil
+}
+
+// apply applies a batch received from a peer to the engine. Operations are only
+// applied if they're newer than the last version applied to their key.
+func (db *DB) apply(b Batch) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	hb := db.mu.heartbeat
+	if b.Heartbeat.OlderThan(hb) {
+		hb = b.Heartbeat
+	}
+	var (
+		v  = b.version()
+		eb = db.Engine.NewBatch()
+	)
+	for _, op := range b.Operations {
+		if reserved(op.Key) {
+			continue
+		}
+		cur, _, err := getVersion(eb, op.Key)
+		if err != nil && !errors.Is(err, kv.NotFound) {
+			return errors.CombineErrors(err, eb.Close())
+		}
+		if err == nil && !v.NewerThan(cur) {
+			continue
+		}
+		if err := applyOperation(eb, op); err != nil {
+			return errors.CombineErrors(err, eb.Close())
+		}
+		if err := setVersion(eb, op.Key, v, op.Variant == VariantDelete); err != nil {
+			return errors.CombineErrors(err, eb.Close())
+		}
+	}
+	if err := setHeartbeat(eb, hb); err != nil {
+		return errors.CombineErrors(err, eb.Close())
+	}
+	if err := eb.Commit(); err != nil {
+		return err
+	}
+	db.mu.heartbeat = hb
+	return nil
+}
+
+func applyOperation(w kv.Writer, op Operation) error {
+	if op.Variant == VariantDelete {
+		if err := w.Delete(op.Key); err != nil && !errors.Is(err, kv.NotFound) {
+			return err
+		}
+		return nil
+	}
+	return w.Set(op.Key, op.Value)
+}
+
+type batch struct {
+	db *DB
+	kv.Batch
+	ops []Operation
+}
+
+// Set implements kv.Batch.
+func (b *batch) Set(key []byte, value []byte, opts ...interface{}) error {
+	if reserved(key) {
+		return ReservedKey
+	}
+	op := Operation{
+		Variant: VariantSet,
+		Key:     binary.MakeCopy(key),
+		Value:   binary.MakeCopy(value),
+	}
+	if err := b.Batch.Set(op.Key, op.Value, opts...); err != nil {
+		return err
+	}
+	b.ops = append(b.ops, op)
+	return nil
+}
+
+// Delete implements kv.Batch.
+func (b *batch) Delete(key []byte) error {
+	if reserved(key) {
+		return ReservedKey
+	}
+	op := Operation{Variant: VariantDelete, Key: binary.MakeCopy(key)}
+	if err := applyOperation(b.Batch, op); err != nil {
+		return err
+	}
+	b.ops = append(b.ops, op)
+	return nil
+}
+
+// Get implements kv.Batch.
+func (b *batch) Get(key []byte, opts ...interface{}) ([]byte, error) {
+	if reserved(key) {
+		return nil, kv.NotFound
+	}
+	return b.Batch.Get(key, opts...)
+}
+
+// NewIterator implements kv.Batch.
+func (b *batch) NewIterator(opts kv.IteratorOptions) kv.Iterator {
+	return b.Batch.NewIterator(clampBounds(opts))
+}
+
+// Commit implements kv.Batch.
+func (b *batch) Commit(opts ...interface{}) error {
+	if len(b.ops) == 0 {
+		return b.Batch.Close()
+	}
+	return b.db.commit(b.Batch, b.ops, opts...)
+}
@@ -0,0 +1,13 @@
+package replicakv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReplicakv(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replicakv Suite")
+}
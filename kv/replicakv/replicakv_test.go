@@ -0,0 +1,185 @@
+package replicakv_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/kv/memkv"
+	"github.com/arya-analytics/x/kv/replicakv"
+	"github.com/arya-analytics/x/signal"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Replicakv", func() {
+	var (
+		addresses = []address.Address{"localhost:0", "localhost:1", "localhost:2"}
+		ctx       signal.Context
+		cancel    context.CancelFunc
+		net       *tmock.Network[replicakv.Batch, replicakv.Ack]
+		engines   []kv.DB
+		dbs       []*replicakv.DB
+	)
+	open := func() {
+		ctx, cancel = signal.WithCancel(context.TODO())
+		net = tmock.NewNetwork[replicakv.Batch, replicakv.Ack]()
+		transports := make([]*tmock.Stream[replicakv.Batch, replicakv.Ack], len(addresses))
+		for i, addr := range addresses {
+			transports[i] = net.RouteStream(addr, 0)
+		}
+		dbs = make([]*replicakv.DB, len(addresses))
+		for i, addr := range addresses {
+			var peers []address.Address
+			for _, other := range addresses {
+				if other != addr {
+					peers = append(peers, other)
+				}
+			}
+			db, err := replicakv.Open(ctx, replicakv.Config{
+				Address:   addr,
+				Peers:     peers,
+				Transport: transports[i],
+				Engine:    engines[i],
+			}, replicakv.WithRetryInterval(time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+			dbs[i] = db
+		}
+	}
+	shutdown := func() {
+		cancel()
+		Expect(errors.Is(ctx.Wait(), context.Canceled)).To(BeTrue())
+	}
+	BeforeEach(func() {
+		engines = []kv.DB{memkv.New(), memkv.New(), memkv.New()}
+		open()
+	})
+	AfterEach(func() {
+		shutdown()
+		for _, db := range dbs {
+			Expect(db.Close()).To(Succeed())
+		}
+	})
+	Describe("Set", func() {
+		It("Should replicate the value to all peers", func() {
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			for _, db := range dbs {
+				Eventually(func() ([]byte, error) {
+					return db.Get([]byte("key"))
+				}).Should(Equal([]byte("value")))
+			}
+		})
+	})
+	Describe("Delete", func() {
+		It("Should replicate the deletion to all peers", func() {
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			Eventually(func() ([]byte, error) {
+				return dbs[2].Get([]byte("key"))
+			}).Should(Equal([]byte("value")))
+			Expect(dbs[1].Delete([]byte("key"))).To(Succeed())
+			for _, db := range dbs {
+				Eventually(func() error {
+					_, err := db.Get([]byte("key"))
+					return err
+				}).Should(MatchError(kv.NotFound))
+			}
+		})
+	})
+	Describe("Batch", func() {
+		It("Should replicate all operations in the batch", func() {
+			b := dbs[1].NewBatch()
+			Expect(b.Set([]byte("a"), []byte("1"))).To(Succeed())
+			Expect(b.Set([]byte("b"), []byte("2"))).To(Succeed())
+			Expect(b.Commit()).To(Succeed())
+			Eventually(func() ([]byte, error) {
+				return dbs[0].Get([]byte("b"))
+			}).Should(Equal([]byte("2")))
+			Expect(dbs[0].Get([]byte("a"))).To(Equal([]byte("1")))
+		})
+	})
+	Describe("Conflicts", func() {
+		It("Should converge on the same value for concurrent writes", func() {
+			for i, db := range dbs {
+				Expect(db.Set([]byte("key"), []byte{byte(i)})).To(Succeed())
+			}
+			Eventually(func() bool {
+				first, err := dbs[0].Get([]byte("key"))
+				if err != nil {
+					return false
+				}
+				for _, db := range dbs[1:] {
+					v, err := db.Get([]byte("key"))
+					if err != nil || v[0] != first[0] {
+						return false
+					}
+				}
+				return true
+			}).Should(BeTrue())
+		})
+		It("Should not resurrect a deleted key with a stale write", func() {
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			Eventually(func() ([]byte, error) {
+				return dbs[1].Get([]byte("key"))
+			}).Should(Equal([]byte("value")))
+			Expect(dbs[1].Delete([]byte("key"))).To(Succeed())
+			Eventually(func() error {
+				_, err := dbs[0].Get([]byte("key"))
+				return err
+			}).Should(MatchError(kv.NotFound))
+			Consistently(func() error {
+				_, err := dbs[2].Get([]byte("key"))
+				return err
+			}, 50*time.Millisecond).Should(MatchError(kv.NotFound))
+		})
+	})
+	Describe("Reserved Keys", func() {
+		It("Should reject writes to the metadata keyspace", func() {
+			Expect(dbs[0].Set([]byte{0xFF, 'a'}, []byte("value"))).
+				To(MatchError(replicakv.ReservedKey))
+			Expect(dbs[0].Delete([]byte{0xFF, 'a'})).To(MatchError(replicakv.ReservedKey))
+		})
+		It("Should hide metadata from reads and iterators", func() {
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			_, err := dbs[0].Get([]byte{0xFF, 'h'})
+			Expect(err).To(MatchError(kv.NotFound))
+			iter := dbs[0].NewIterator(kv.IteratorOptions{})
+			var keys [][]byte
+			for iter.First(); iter.Valid(); iter.Next() {
+				keys = append(keys, iter.Key())
+			}
+			Expect(iter.Close()).To(Succeed())
+			Expect(keys).To(Equal([][]byte{[]byte("key")}))
+		})
+	})
+	Describe("Reopen", func() {
+		It("Should restart the heartbeat and retain replicated data", func() {
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			Eventually(func() ([]byte, error) {
+				return dbs[2].Get([]byte("key"))
+			}).Should(Equal([]byte("value")))
+			gen := dbs[2].Heartbeat().Generation
+			shutdown()
+			open()
+			Expect(dbs[2].Heartbeat().Generation).To(Equal(gen + 1))
+			Expect(dbs[2].Heartbeat().Version).To(BeZero())
+			Expect(dbs[2].Get([]byte("key"))).To(Equal([]byte("value")))
+		})
+		It("Should deliver batches that weren't acknowledged before the restart", func() {
+			net.Partition(addresses[:1], addresses[1:])
+			Expect(dbs[0].Set([]byte("key"), []byte("value"))).To(Succeed())
+			Consistently(func() error {
+				_, err := dbs[1].Get([]byte("key"))
+				return err
+			}, 20*time.Millisecond).Should(MatchError(kv.NotFound))
+			shutdown()
+			open()
+			for _, db := range dbs[1:] {
+				Eventually(func() ([]byte, error) {
+					return db.Get([]byte("key"))
+				}).Should(Equal([]byte("value")))
+			}
+		})
+	})
+})
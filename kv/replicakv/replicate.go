@@ -0,0 +1,154 @@
+package replicakv
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/signal"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// peer is a queue of batches waiting to be acknowledged by a remote replica.
+type peer struct {
+	addr address.Address
+	// acked is the sequence number of the last entry in the outbound log
+	// acknowledged by the peer. It's guarded by the DB's mutex.
+	acked uint64
+	mu    sync.Mutex
+	// pending are the entries in the outbound log that have not been acknowledged by
+	// the peer, in commit order.
+	pending []logEntry
+	// notify is signalled whenever an entry is pushed to the queue.
+	notify chan struct{}
+}
+
+func newPeer(addr address.Address, acked uint64) *peer {
+	return &peer{addr: addr, acked: acked, notify: make(chan struct{}, 1)}
+}
+
+func (p *peer) push(e logEntry) {
+	p.mu.Lock()
+	p.pending = append(p.pending, e)
+	p.mu.Unlock()
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (p *peer) peek() (logEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 {
+		return logEntry{}, false
+	}
+	return p.pending[0], true
+}
+
+func (p *peer) pop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending = p.pending[1:]
+}
+
+// replicate forwards batches to the peer in commit order. A batch is only removed
+// from the queue, and from the outbound log, once the peer acknowledges it. If the
+// stream to the peer breaks, it's re-opened and the unacknowledged batch is re-sent.
+// This is safe, as applying the same batch twice has no effect.
+func (db *DB) replicate(ctx signal.Context, p *peer) error {
+	var s peerStream
+	defer s.close()
+	for {
+		e, ok := p.peek()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.notify:
+				continue
+			}
+		}
+		err := db.deliver(ctx, &s, p.addr, e.batch)
+		if err == nil {
+			p.pop()
+			if err = db.acknowledge(p, e.seq); err != nil {
+				return err
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		db.logger.Warn("failed to replicate batch",
+			zap.Stringer("peer", p.addr),
+			zap.Error(err),
+		)
+		s.close()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(db.retryInterval):
+		}
+	}
+}
+
+// peerStream is the stream batches are replicated to a peer over.
+type peerStream struct {
+	client transport.StreamClient[Batch, Ack]
+	cancel context.CancelFunc
+}
+
+// close closes the stream and releases its resources. close is a no-op if the
+// stream isn't open.
+func (s *peerStream) close() {
+	if s.client == nil {
+		return
+	}
+	_ = s.client.CloseSend()
+	s.cancel()
+	s.client, s.cancel = nil, nil
+}
+
+func (db *DB) deliver(
+	ctx context.Context,
+	s *peerStream,
+	target address.Address,
+	b Batch,
+) (err error) {
+	if s.client == nil {
+		sCtx, cancel := context.WithCancel(ctx)
+		if s.client, err = db.Transport.Stream(sCtx, target); err != nil {
+			cancel()
+			return err
+		}
+		s.cancel = cancel
+	}
+	if err = s.client.Send(b); err != nil {
+		return err
+	}
+	_, err = s.client.Receive()
+	return err
+}
+
+// handle applies batches received from a peer, acknowledging each one once it has
+// been applied.
+func (db *DB) handle(_ context.Context, srv transport.StreamServer[Batch, Ack]) error {
+	for {
+		b, err := srv.Receive()
+		if errors.Is(err, transport.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = db.apply(b); err != nil {
+			return err
+		}
+		if err = srv.Send(Ack{Heartbeat: b.Heartbeat}); err != nil {
+			return err
+		}
+	}
+}
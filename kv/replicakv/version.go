@@ -0,0 +1,120 @@
+package replicakv
+
+import (
+	"bytes"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/binary"
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/version"
+	"github.com/cockroachdb/errors"
+)
+
+// Version is the version of the last operation applied to a key.
+type Version struct {
+	// Heartbeat is the heartbeat of the node that committed the operation.
+	Heartbeat version.Heartbeat
+	// Origin is the address of the node that committed the operation.
+	Origin address.Address
+}
+
+// NewerThan returns true if the Version should take precedence over other. Note that
+// an 'older' heartbeat represents a newer version.
+func (v Version) NewerThan(other Version) bool {
+	if v.Heartbeat.OlderThan(other.Heartbeat) {
+		return true
+	}
+	if v.Heartbeat.YoungerThan(other.Heartbeat) {
+		return false
+	}
+	return v.Origin > other.Origin
+}
+
+const metaPrefix byte = 0xFF
+
+var (
+	heartbeatKey     = []byte{metaPrefix, 'h'}
+	versionKeyPrefix = []byte{metaPrefix, 'v'}
+	// metaLowerBound is the first key in the reserved metadata keyspace.
+	metaLowerBound = []byte{metaPrefix}
+)
+
+func reserved(key []byte) bool { return len(key) > 0 && key[0] == metaPrefix }
+
+func versionKey(key []byte) []byte {
+	return append(binary.MakeCopy(versionKeyPrefix), key...)
+}
+
+// clampBounds prevents an iterator from reaching the metadata keyspace.
+func clampBounds(opts kv.IteratorOptions) kv.IteratorOptions {
+	if opts.UpperBound == nil || bytes.Compare(opts.UpperBound, metaLowerBound) > 0 {
+		opts.UpperBound = metaLowerBound
+	}
+	return opts
+}
+
+// |||||| ENCODING ||||||
+
+// encodedHeartbeatSize is the size of a binary encoded version.Heartbeat.
+const encodedHeartbeatSize = 8
+
+func encodeVersion(v Version, deleted bool) ([]byte, error) {
+	b := new(bytes.Buffer)
+	if err := binary.Write(b, v.Heartbeat); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(b, deleted); err != nil {
+		return nil, err
+	}
+	b.WriteString(string(v.Origin))
+	return b.Bytes(), nil
+}
+
+func decodeVersion(b []byte) (v Version, deleted bool, err error) {
+	if len(b) < encodedHeartbeatSize+1 {
+		return v, false, errors.New("[replicakv] - malformed version")
+	}
+	r := bytes.NewReader(b)
+	if err = binary.Read(r, &v.Heartbeat); err != nil {
+		return v, false, err
+	}
+	if err = binary.Read(r, &deleted); err != nil {
+		return v, false, err
+	}
+	v.Origin = address.Address(b[encodedHeartbeatSize+1:])
+	return v, deleted, nil
+}
+
+func getVersion(r kv.Reader, key []byte) (Version, bool, error) {
+	b, err := r.Get(versionKey(key))
+	if err != nil {
+		return Version{}, false, err
+	}
+	return decodeVersion(b)
+}
+
+func setVersion(w kv.Writer, key []byte, v Version, deleted bool) error {
+	b, err := encodeVersion(v, deleted)
+	if err != nil {
+		return err
+	}
+	return w.Set(versionKey(key), b)
+}
+
+func loadHeartbeat(r kv.Reader) (hb version.Heartbeat, err error) {
+	b, err := r.Get(heartbeatKey)
+	if errors.Is(err, kv.NotFound) {
+		return hb, nil
+	}
+	if err != nil {
+		return hb, err
+	}
+	return hb, binary.Read(bytes.NewReader(b), &hb)
+}
+
+func setHeartbeat(w kv.Writer, hb version.Heartbeat) error {
+	b, err := binary.Marshal(hb)
+	if err != nil {
+		return err
+	}
+	return w.Set(heartbeatKey, b)
+}
@@ -0,0 +1,123 @@
+package kv
+
+import (
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// Sequence allocates unique, monotonically increasing int64 IDs starting at 1. Rather
+// than persisting every ID it issues, a Sequence leases blocks of IDs from a
+// PersistedCounter, and only flushes the high-water mark of the most recently leased
+// block. If the process crashes, IDs remaining in the leased block are discarded, so
+// an ID is never issued twice (although gaps may appear in the sequence).
+//
+// Sequence is safe for concurrent use. To open a Sequence, call OpenSequence. To
+// manage multiple named sequences within a single DB, use a Sequencer.
+type Sequence struct {
+	mu        sync.Mutex
+	counter   *PersistedCounter
+	blockSize int64
+	// next is the next ID to issue.
+	next int64
+}
+
+// DefaultSequenceBlockSize is the number of IDs leased by a Sequence at a time when
+// a non-positive block size is provided.
+const DefaultSequenceBlockSize int64 = 100
+
+// OpenSequence opens or creates a Sequence that persists its high-water mark under
+// the given key. blockSize sets the number of IDs leased from the DB at a time. A
+// larger block size results in fewer writes, but more IDs discarded on a crash.
+func OpenSequence(db DB, key []byte, blockSize int64) (*Sequence, error) {
+	if blockSize <= 0 {
+		blockSize = DefaultSequenceBlockSize
+	}
+	counter, err := NewPersistedCounter(db, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Sequence{counter: counter, blockSize: blockSize, next: counter.Value() + 1}, nil
+}
+
+// Next returns the next ID in the sequence.
+func (s *Sequence) Next() (int64, error) { return s.NextN(1) }
+
+// NextN reserves n contiguous IDs, and returns the first ID in the range.
+func (s *Sequence) NextN(n int64) (int64, error) {
+	if n <= 0 {
+		return 0, errors.Newf("[kv] - cannot reserve %v ids from sequence", n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if short := s.next + n - 1 - s.counter.Value(); short > 0 {
+		lease := s.blockSize
+		if short > lease {
+			lease = short
+		}
+		// The counter is left unchanged if the lease fails to persist, so the
+		// remaining IDs in the current block are still safe to issue.
+		if _, err := s.counter.Increment(lease); err != nil {
+			return 0, err
+		}
+	}
+	start := s.next
+	s.next += n
+	return start, nil
+}
+
+// HighWaterMark returns the highest ID that has been leased from the DB.
+func (s *Sequence) HighWaterMark() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counter.Value()
+}
+
+// Sequencer manages a set of named Sequences stored under a common key prefix in a
+// single DB. Sequencer is safe for concurrent use.
+type Sequencer struct {
+	db        DB
+	prefix    []byte
+	blockSize int64
+	mu        sync.Mutex
+	sequences map[string]*Sequence
+}
+
+// NewSequencer creates a new Sequencer that stores the high-water mark of each
+// sequence under the given prefix. blockSize sets the number of IDs leased at a time
+// by each sequence. See OpenSequence for more details.
+func NewSequencer(db DB, prefix []byte, blockSize int64) *Sequencer {
+	return &Sequencer{
+		db:        db,
+		prefix:    prefix,
+		blockSize: blockSize,
+		sequences: make(map[string]*Sequence),
+	}
+}
+
+// Sequence opens the sequence with the given name, creating it if it doesn't exist.
+func (s *Sequencer) Sequence(name string) (*Sequence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq, ok := s.sequences[name]; ok {
+		return seq, nil
+	}
+	key, err := CompositeKey(s.prefix, name)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := OpenSequence(s.db, key, s.blockSize)
+	if err != nil {
+		return nil, err
+	}
+	s.sequences[name] = seq
+	return seq, nil
+}
+
+// Next returns the next ID in the sequence with the given name.
+func (s *Sequencer) Next(name string) (int64, error) {
+	seq, err := s.Sequence(name)
+	if err != nil {
+		return 0, err
+	}
+	return seq.Next()
+}
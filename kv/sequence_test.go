@@ -0,0 +1,96 @@
+package kv_test
+
+import (
+	"github.com/arya-analytics/x/kv"
+	"github.com/arya-analytics/x/kv/memkv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync"
+)
+
+var _ = Describe("Sequence", func() {
+	var kve kv.DB
+	BeforeEach(func() { kve = memkv.New() })
+	AfterEach(func() { Expect(kve.Close()).To(Succeed()) })
+	Describe("Next", func() {
+		It("Should issue sequential IDs starting at 1", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seq.Next()).To(Equal(int64(1)))
+			Expect(seq.Next()).To(Equal(int64(2)))
+			Expect(seq.HighWaterMark()).To(Equal(int64(10)))
+		})
+		It("Should lease a new block once the current one is exhausted", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 2)
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 3; i++ {
+				_, err := seq.Next()
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(seq.HighWaterMark()).To(Equal(int64(4)))
+		})
+	})
+	Describe("NextN", func() {
+		It("Should reserve a contiguous range larger than the block size", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seq.Next()).To(Equal(int64(1)))
+			Expect(seq.NextN(5)).To(Equal(int64(2)))
+			Expect(seq.Next()).To(Equal(int64(7)))
+		})
+		It("Should return an error when reserving a non-positive number of IDs", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 2)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = seq.NextN(0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("Recovery", func() {
+		It("Should never reissue an ID after re-opening", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seq.Next()).To(Equal(int64(1)))
+			seqTwo, err := kv.OpenSequence(kve, []byte("seq"), 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seqTwo.Next()).To(Equal(int64(11)))
+		})
+	})
+	Describe("Concurrency", func() {
+		It("Should issue unique IDs to concurrent callers", func() {
+			seq, err := kv.OpenSequence(kve, []byte("seq"), 7)
+			Expect(err).ToNot(HaveOccurred())
+			var (
+				wg  sync.WaitGroup
+				mu  sync.Mutex
+				ids = make(map[int64]struct{})
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						id, err := seq.Next()
+						Expect(err).ToNot(HaveOccurred())
+						mu.Lock()
+						ids[id] = struct{}{}
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(ids).To(HaveLen(1000))
+		})
+	})
+	Describe("Sequencer", func() {
+		It("Should manage independent named sequences", func() {
+			s := kv.NewSequencer(kve, []byte("seq/"), 10)
+			Expect(s.Next("a")).To(Equal(int64(1)))
+			Expect(s.Next("a")).To(Equal(int64(2)))
+			Expect(s.Next("b")).To(Equal(int64(1)))
+			sTwo := kv.NewSequencer(kve, []byte("seq/"), 10)
+			Expect(sTwo.Next("a")).To(Equal(int64(11)))
+			Expect(sTwo.Next("b")).To(Equal(int64(11)))
+		})
+	})
+})
@@ -2,6 +2,8 @@ package kv
 
 import (
 	"bytes"
+	"github.com/arya-analytics/x/binary"
+	"github.com/cockroachdb/errors"
 	"io"
 )
 
@@ -41,3 +43,46 @@ func Load(kv Reader, key []byte, loader Loader) error {
 
 // LoadBytes loads the contents of a byte slice into a Loader.
 func LoadBytes(b []byte, loader Loader) error { return loader.Load(bytes.NewReader(b)) }
+
+// FlushLoaderEncoderDecoder is a binary.EncoderDecoder that encodes values using their
+// Flusher implementation, and decodes values using their Loader implementation. It
+// allows types that implement FlushLoader to be stored in a Persisted.
+type FlushLoaderEncoderDecoder struct{}
+
+var _ binary.EncoderDecoder = (*FlushLoaderEncoderDecoder)(nil)
+
+// Encode implements the binary.Encoder interface.
+func (e *FlushLoaderEncoderDecoder) Encode(value interface{}) ([]byte, error) {
+	f, ok := value.(Flusher)
+	if !ok {
+		return nil, errors.Newf("[kv] - %T does not implement Flusher", value)
+	}
+	b := new(bytes.Buffer)
+	err := f.Flush(b)
+	return b.Bytes(), err
+}
+
+// EncodeStatic implements the binary.Encoder interface.
+func (e *FlushLoaderEncoderDecoder) EncodeStatic(value interface{}) []byte {
+	b, err := e.Encode(value)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Decode implements the binary.Decoder interface.
+func (e *FlushLoaderEncoderDecoder) Decode(data []byte, value interface{}) error {
+	l, ok := value.(Loader)
+	if !ok {
+		return errors.Newf("[kv] - %T does not implement Loader", value)
+	}
+	return LoadBytes(data, l)
+}
+
+// DecodeStatic implements the binary.Decoder interface.
+func (e *FlushLoaderEncoderDecoder) DecodeStatic(data []byte, value interface{}) {
+	if err := e.Decode(data, value); err != nil {
+		panic(err)
+	}
+}
@@ -1,6 +1,7 @@
 package observe
 
 import (
+	"github.com/arya-analytics/x/binary"
 	"github.com/arya-analytics/x/kv"
 	"github.com/arya-analytics/x/signal"
 	"time"
@@ -8,13 +9,17 @@ import (
 
 // |||||| FLUSH ||||||
 
-// FlushSubscriber is used to flush an observable whose contents implement
-//  the kv.Flusher interface.
-type FlushSubscriber[S kv.Flusher] struct {
+// FlushSubscriber is used to flush the contents of an observable to a kv.DB. To
+// observe and reload the flushed value as well, use a kv.Persisted instead.
+type FlushSubscriber[S any] struct {
 	// Key is the key to flush the contents of the observable into.
 	Key []byte
 	// Store is the store to flush the contents of the observable into.
 	Store kv.DB
+	// Codec is used to encode the contents of the observable. If Codec is nil, the
+	// contents must implement the kv.Flusher interface, and are encoded using a
+	// kv.FlushLoaderEncoderDecoder.
+	Codec binary.EncoderDecoder
 	// MinInterval specifies the minimum interval between flushes. If the observable
 	// updates more quickly than min interval, the FlushSubscriber will not flush the
 	// contents.
@@ -34,10 +39,22 @@ func (f *FlushSubscriber[S]) Flush(state S) {
 }
 
 func (f *FlushSubscriber[S]) FlushSync(state S) {
-	err := kv.Flush(f.Store, f.Key, state)
+	err := f.flush(state)
 	if err != nil && f.Errors != nil {
 		f.Errors.Transient() <- err
 	} else {
 		f.LastFlush = time.Now()
 	}
 }
+
+func (f *FlushSubscriber[S]) flush(state S) error {
+	codec := f.Codec
+	if codec == nil {
+		codec = &kv.FlushLoaderEncoderDecoder{}
+	}
+	b, err := codec.Encode(state)
+	if err != nil {
+		return err
+	}
+	return f.Store.Set(f.Key, b)
+}
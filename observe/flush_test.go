@@ -1,6 +1,7 @@
 package observe_test
 
 import (
+	"github.com/arya-analytics/x/binary"
 	"github.com/arya-analytics/x/kv/memkv"
 	"github.com/arya-analytics/x/observe"
 	. "github.com/onsi/ginkgo/v2"
@@ -30,4 +31,16 @@ var _ = Describe("Flush", func() {
 		o.Notify(simpleFlusher{value: []byte("hello")})
 		Expect(kv.Get([]byte("key"))).To(Equal([]byte("hello")))
 	})
+	It("Should flush the observable contents using the provided codec", func() {
+		o := observe.New[int64]()
+		kv := memkv.New()
+		flush := &observe.FlushSubscriber[int64]{
+			Key:   []byte("key"),
+			Store: kv,
+			Codec: &binary.FixedEncoderDecoder{},
+		}
+		o.OnChange(flush.FlushSync)
+		o.Notify(int64(1))
+		Expect(kv.Get([]byte("key"))).To(Equal([]byte{0, 0, 0, 0, 0, 0, 0, 1}))
+	})
 })
@@ -15,6 +15,10 @@ type Factory[K comparable, A Adapter] interface {
 
 type Pool[K comparable, A Adapter] interface {
 	Acquire(key K) (A, error)
+	// Healthy returns true if the pool holds a healthy adapter for the key. ok is
+	// false if the pool holds no adapters for the key. Unlike Acquire, Healthy never
+	// creates new adapters.
+	Healthy(key K) (healthy bool, ok bool)
 }
 
 func New[K comparable, A Adapter](factory Factory[K, A]) Pool[K, A] {
@@ -40,6 +44,21 @@ func (p *core[K, A]) Acquire(key K) (A, error) {
 	return p.new(key)
 }
 
+func (p *core[K, A]) Healthy(key K) (healthy bool, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	adapters, ok := p.pool[key]
+	if !ok {
+		return false, false
+	}
+	for _, adapter := range adapters {
+		if adapter.Healthy() {
+			return true, true
+		}
+	}
+	return false, true
+}
+
 func (p *core[K, A]) new(key K) (a A, err error) {
 	a, err = p.factory.New(key)
 	if err != nil {
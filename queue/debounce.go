@@ -41,7 +41,9 @@ func (d *Debounce[V]) Flow(ctx signal.Context, opts ...confluence.Option) {
 			if len(values) == 0 {
 				continue
 			}
-			d.Out.Inlet() <- values
+			if err := confluence.Send(d.Out, values); err != nil {
+				return err
+			}
 		}
 	}, fo.Signal...)
 }
@@ -0,0 +1,185 @@
+// Package balance implements client-side load balancing across a dynamic set of
+// targets for transport.UnaryClient and transport.Stream.
+//
+// A Balancer picks a target for every request using a Strategy, skipping targets
+// reported unhealthy by its HealthCheck. The set of targets can be replaced at any
+// time, either by calling SetTargets or by passing an observable using WithUpdates.
+package balance
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// Unavailable is returned when a Balancer has no healthy targets to pick from.
+var Unavailable = errors.New("[balance] - no healthy targets")
+
+// Balancer balances requests across a set of targets. To create a new Balancer, call
+// New.
+type Balancer[I, O transport.Message] struct {
+	strategy    Strategy[I]
+	healthy     HealthCheck
+	mu          sync.Mutex
+	targets     []address.Address
+	outstanding map[address.Address]int
+}
+
+// New creates a new Balancer that picks targets using the provided strategy.
+func New[I, O transport.Message](strategy Strategy[I], opts ...Option) *Balancer[I, O] {
+	o := newOptions(opts...)
+	b := &Balancer[I, O]{
+		strategy:    strategy,
+		healthy:     o.healthy,
+		outstanding: make(map[address.Address]int),
+	}
+	b.SetTargets(o.targets)
+	if o.updates != nil {
+		o.updates.OnChange(b.SetTargets)
+	}
+	return b
+}
+
+// Targets returns the current set of targets.
+func (b *Balancer[I, O]) Targets() []address.Address {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]address.Address(nil), b.targets...)
+}
+
+// SetTargets replaces the set of targets. Requests already sent to removed targets
+// are unaffected.
+func (b *Balancer[I, O]) SetTargets(targets []address.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.targets = append([]address.Address(nil), targets...)
+}
+
+// Pick returns the target the request should be sent to without sending it. Pick
+// returns Unavailable if there are no healthy targets.
+func (b *Balancer[I, O]) Pick(req I) (address.Address, error) {
+	targets := b.Targets()
+	candidates := make([]Candidate, 0, len(targets))
+	for _, t := range targets {
+		// The health check may dial the target, so we run it without holding the
+		// lock.
+		if b.healthy == nil || b.healthy(t) {
+			candidates = append(candidates, Candidate{Address: t})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", Unavailable
+	}
+	b.mu.Lock()
+	for i := range candidates {
+		candidates[i].Outstanding = b.outstanding[candidates[i].Address]
+	}
+	b.mu.Unlock()
+	return b.strategy(candidates, req), nil
+}
+
+// Send sends the request to a target picked by the Balancer using the provided
+// client.
+func (b *Balancer[I, O]) Send(
+	ctx context.Context,
+	client transport.UnaryClient[I, O],
+	req I,
+) (res O, err error) {
+	target, err := b.Pick(req)
+	if err != nil {
+		return res, err
+	}
+	b.acquire(target)
+	defer b.release(target)
+	return client.Send(ctx, target, req)
+}
+
+// Stream opens a stream to a target picked by the Balancer using the provided
+// transport. The target is picked as if key were the request being sent. The stream
+// counts as an outstanding request until Receive returns an error or ctx is
+// cancelled, so callers that abandon a stream should cancel its context.
+func (b *Balancer[I, O]) Stream(
+	ctx context.Context,
+	stream transport.Stream[I, O],
+	key I,
+) (transport.StreamClient[I, O], error) {
+	target, err := b.Pick(key)
+	if err != nil {
+		return nil, err
+	}
+	b.acquire(target)
+	client, err := stream.Stream(ctx, target)
+	if err != nil {
+		b.release(target)
+		return nil, err
+	}
+	s := &streamClient[I, O]{
+		StreamClient: client,
+		release:      func() { b.release(target) },
+		released:     make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.done()
+		case <-s.released:
+		}
+	}()
+	return s, nil
+}
+
+// Client returns a transport.UnaryClient that ignores the target passed to Send and
+// sends requests to a target picked by the Balancer instead.
+func (b *Balancer[I, O]) Client(client transport.UnaryClient[I, O]) transport.UnaryClient[I, O] {
+	return balancedClient[I, O]{balancer: b, client: client}
+}
+
+func (b *Balancer[I, O]) acquire(target address.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.outstanding[target]++
+}
+
+func (b *Balancer[I, O]) release(target address.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.outstanding[target]--; b.outstanding[target] <= 0 {
+		delete(b.outstanding, target)
+	}
+}
+
+type balancedClient[I, O transport.Message] struct {
+	balancer *Balancer[I, O]
+	client   transport.UnaryClient[I, O]
+}
+
+// Send implements the transport.UnaryClient interface.
+func (c balancedClient[I, O]) Send(ctx context.Context, _ address.Address, req I) (O, error) {
+	return c.balancer.Send(ctx, c.client, req)
+}
+
+type streamClient[I, O transport.Message] struct {
+	transport.StreamClient[I, O]
+	once    sync.Once
+	release func()
+	// released is closed once the stream no longer counts as outstanding.
+	released chan struct{}
+}
+
+// Receive implements the transport.StreamReceiver interface.
+func (s *streamClient[I, O]) Receive() (res O, err error) {
+	res, err = s.StreamClient.Receive()
+	if err != nil {
+		s.done()
+	}
+	return res, err
+}
+
+func (s *streamClient[I, O]) done() {
+	s.once.Do(func() {
+		s.release()
+		close(s.released)
+	})
+}
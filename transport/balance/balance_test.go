@@ -0,0 +1,220 @@
+package balance_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/observe"
+	"github.com/arya-analytics/x/pool"
+	"github.com/arya-analytics/x/transport"
+	"github.com/arya-analytics/x/transport/balance"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strconv"
+)
+
+type adapter struct{ healthy bool }
+
+func (a *adapter) Healthy() bool  { return a.healthy }
+func (a *adapter) Close() error   { return nil }
+func (a *adapter) Acquire() error { return nil }
+func (a *adapter) Release()       {}
+
+type factory map[address.Address]*adapter
+
+func (f factory) New(target address.Address) (*adapter, error) { return f[target], nil }
+
+type countingFactory struct {
+	factory
+	created *int
+}
+
+func (f countingFactory) New(target address.Address) (*adapter, error) {
+	*f.created++
+	return f.factory.New(target)
+}
+
+var _ = Describe("Balancer", func() {
+	var (
+		net     *tmock.Network[string, address.Address]
+		client  *tmock.Unary[string, address.Address]
+		targets []address.Address
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[string, address.Address]()
+		client = net.RouteUnary("localhost:0")
+		targets = nil
+		for i := 1; i <= 3; i++ {
+			t := net.RouteUnary("")
+			t.Handle(func(ctx context.Context, _ string) (address.Address, error) {
+				return t.Address, nil
+			})
+			targets = append(targets, t.Address)
+		}
+	})
+	Describe("RoundRobin", func() {
+		It("Should cycle through the targets", func() {
+			b := balance.New[string, address.Address](
+				balance.RoundRobin[string](),
+				balance.WithTargets(targets...),
+			)
+			for i := 0; i < 6; i++ {
+				res, err := b.Send(ctx, client, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(targets[i%3]))
+			}
+		})
+	})
+	Describe("LeastOutstanding", func() {
+		It("Should pick the target with the fewest open streams", func() {
+			snet := tmock.NewNetwork[string, address.Address]()
+			t1 := snet.RouteStream("localhost:0", 1)
+			var stargets []address.Address
+			for i := 0; i < 3; i++ {
+				t := snet.RouteStream("", 1)
+				t.Handle(func(ctx context.Context, srv transport.StreamServer[string, address.Address]) error {
+					_, err := srv.Receive()
+					return err
+				})
+				stargets = append(stargets, t.Address)
+			}
+			b := balance.New[string, address.Address](
+				balance.LeastOutstanding[string](),
+				balance.WithTargets(stargets...),
+			)
+			var streams []transport.StreamClient[string, address.Address]
+			for i := 0; i < 3; i++ {
+				stream, err := b.Stream(ctx, t1, "")
+				Expect(err).ToNot(HaveOccurred())
+				streams = append(streams, stream)
+			}
+			Expect(b.Pick("")).To(Equal(stargets[0]))
+			// Closing the second stream should make its target the least loaded.
+			Expect(streams[1].CloseSend()).To(Succeed())
+			_, err := streams[1].Receive()
+			Expect(err).To(HaveOccurred())
+			Expect(b.Pick("")).To(Equal(stargets[1]))
+		})
+		It("Should stop counting a stream once its context is cancelled", func() {
+			snet := tmock.NewNetwork[string, address.Address]()
+			t1 := snet.RouteStream("localhost:0", 1)
+			var stargets []address.Address
+			for i := 0; i < 2; i++ {
+				t := snet.RouteStream("", 1)
+				t.Handle(func(ctx context.Context, srv transport.StreamServer[string, address.Address]) error {
+					_, err := srv.Receive()
+					return err
+				})
+				stargets = append(stargets, t.Address)
+			}
+			b := balance.New[string, address.Address](
+				balance.LeastOutstanding[string](),
+				balance.WithTargets(stargets...),
+			)
+			sCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			_, err := b.Stream(sCtx, t1, "")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = b.Stream(ctx, t1, "")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = b.Stream(ctx, t1, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Pick("")).To(Equal(stargets[1]))
+			cancel()
+			Eventually(func() (address.Address, error) { return b.Pick("") }).Should(Equal(stargets[0]))
+		})
+	})
+	Describe("ConsistentHash", func() {
+		It("Should send requests with the same key to the same target", func() {
+			b := balance.New[string, address.Address](
+				balance.ConsistentHash(func(key string) string { return key }),
+				balance.WithTargets(targets...),
+			)
+			picks := make(map[string]address.Address)
+			for i := 0; i < 100; i++ {
+				key := strconv.Itoa(i)
+				res, err := b.Send(ctx, client, key)
+				Expect(err).ToNot(HaveOccurred())
+				picks[key] = res
+			}
+			Expect(picks).To(ContainElements(targets[0], targets[1], targets[2]))
+			By("Only moving the keys of a removed target")
+			b.SetTargets(targets[:2])
+			for key, prev := range picks {
+				next, err := b.Pick(key)
+				Expect(err).ToNot(HaveOccurred())
+				if prev != targets[2] {
+					Expect(next).To(Equal(prev))
+				} else {
+					Expect(next).ToNot(Equal(targets[2]))
+				}
+			}
+		})
+	})
+	Describe("Updates", func() {
+		It("Should replace the targets when the observable changes", func() {
+			obs := observe.New[[]address.Address]()
+			b := balance.New[string, address.Address](
+				balance.RoundRobin[string](),
+				balance.WithTargets(targets[0]),
+				balance.WithUpdates(obs),
+			)
+			Expect(b.Pick("")).To(Equal(targets[0]))
+			obs.Notify(targets[1:2])
+			Expect(b.Targets()).To(Equal(targets[1:2]))
+			Expect(b.Pick("")).To(Equal(targets[1]))
+		})
+	})
+	Describe("Health", func() {
+		It("Should skip targets reported unhealthy by the pool", func() {
+			adapters := factory{
+				targets[0]: {healthy: false},
+				targets[1]: {healthy: true},
+				targets[2]: {healthy: false},
+			}
+			p := pool.New[address.Address, *adapter](adapters)
+			for _, t := range targets[:2] {
+				_, err := p.Acquire(t)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			b := balance.New[string, address.Address](
+				balance.RoundRobin[string](),
+				balance.WithTargets(targets[:2]...),
+				balance.WithHealthCheck(balance.PoolHealth[*adapter](p)),
+			)
+			for i := 0; i < 3; i++ {
+				res, err := b.Client(client).Send(ctx, "", "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(targets[1]))
+			}
+		})
+		It("Should not create adapters when checking health", func() {
+			created := 0
+			p := pool.New[address.Address, *adapter](countingFactory{
+				factory: factory{targets[0]: {healthy: false}},
+				created: &created,
+			})
+			healthy := balance.PoolHealth[*adapter](p)
+			By("Considering targets the pool hasn't connected to healthy")
+			Expect(healthy(targets[0])).To(BeTrue())
+			Expect(created).To(BeZero())
+			_, err := p.Acquire(targets[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(Equal(1))
+			By("Reporting the health of existing adapters")
+			for i := 0; i < 3; i++ {
+				Expect(healthy(targets[0])).To(BeFalse())
+			}
+			Expect(created).To(Equal(1))
+		})
+		It("Should return Unavailable when no targets are healthy", func() {
+			b := balance.New[string, address.Address](
+				balance.RoundRobin[string](),
+				balance.WithTargets(targets...),
+				balance.WithHealthCheck(func(address.Address) bool { return false }),
+			)
+			_, err := b.Send(ctx, client, "")
+			Expect(err).To(MatchError(balance.Unavailable))
+		})
+	})
+})
@@ -0,0 +1,21 @@
+package balance
+
+import (
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/pool"
+)
+
+// HealthCheck returns true if the target is healthy and should receive requests.
+type HealthCheck func(target address.Address) bool
+
+// PoolHealth returns a HealthCheck that reports the health of the adapters the pool
+// holds for the target. The HealthCheck never acquires or creates adapters, so a
+// target the pool hasn't connected to yet is considered healthy, allowing requests
+// to reach it and establish a connection. To use a grpc.Pool, pass its embedded
+// pool.Pool.
+func PoolHealth[A pool.Adapter](p pool.Pool[address.Address, A]) HealthCheck {
+	return func(target address.Address) bool {
+		healthy, ok := p.Healthy(target)
+		return !ok || healthy
+	}
+}
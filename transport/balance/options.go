@@ -0,0 +1,37 @@
+package balance
+
+import (
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/observe"
+)
+
+type options struct {
+	targets []address.Address
+	updates observe.Observable[[]address.Address]
+	healthy HealthCheck
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTargets sets the initial set of targets to balance across.
+func WithTargets(targets ...address.Address) Option {
+	return func(o *options) { o.targets = targets }
+}
+
+// WithUpdates replaces the set of targets every time the observable changes.
+func WithUpdates(updates observe.Observable[[]address.Address]) Option {
+	return func(o *options) { o.updates = updates }
+}
+
+// WithHealthCheck skips targets that the provided HealthCheck reports as unhealthy.
+func WithHealthCheck(healthy HealthCheck) Option {
+	return func(o *options) { o.healthy = healthy }
+}
@@ -0,0 +1,73 @@
+package balance
+
+import (
+	"github.com/arya-analytics/x/address"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// Candidate is a healthy target that a Strategy can pick.
+type Candidate struct {
+	address.Address
+	// Outstanding is the number of requests and streams sent to the target through
+	// the Balancer that haven't completed.
+	Outstanding int
+}
+
+// Strategy picks the target to send a request to from a non-empty set of candidates.
+// Candidates are always passed in the same order as the targets they were taken from.
+type Strategy[I any] func(candidates []Candidate, req I) address.Address
+
+// RoundRobin returns a Strategy that cycles through the candidates in order.
+func RoundRobin[I any]() Strategy[I] {
+	var next uint64
+	return func(candidates []Candidate, _ I) address.Address {
+		i := atomic.AddUint64(&next, 1) - 1
+		return candidates[i%uint64(len(candidates))].Address
+	}
+}
+
+// LeastOutstanding returns a Strategy that picks the candidate with the fewest
+// outstanding requests, preferring earlier candidates when there is a tie.
+func LeastOutstanding[I any]() Strategy[I] {
+	return func(candidates []Candidate, _ I) address.Address {
+		best := candidates[0]
+		for _, c := range candidates[1:] {
+			if c.Outstanding < best.Outstanding {
+				best = c
+			}
+		}
+		return best.Address
+	}
+}
+
+// ConsistentHash returns a Strategy that picks a candidate based on the key of the
+// request, so that requests with the same key are sent to the same target. When a
+// target is added or removed (or becomes unhealthy), only the keys mapped to that
+// target move.
+//
+// ConsistentHash uses rendezvous hashing, so picking a target is linear in the
+// number of candidates.
+func ConsistentHash[I any](key func(I) string) Strategy[I] {
+	return func(candidates []Candidate, req I) address.Address {
+		k := key(req)
+		var (
+			best  address.Address
+			score uint64
+		)
+		for i, c := range candidates {
+			if s := hash(k, c.Address); i == 0 || s > score {
+				best, score = c.Address, s
+			}
+		}
+		return best
+	}
+}
+
+func hash(key string, target address.Address) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(target))
+	return h.Sum64()
+}
@@ -0,0 +1,319 @@
+// Package flow implements credit-based flow control for transport streams, so that a
+// fast sender can't overwhelm a slow receiver.
+//
+// Each end of a flow controlled stream grants its peer a window of credits. Sending
+// a message consumes a credit, and Send blocks when the sender runs out. As the
+// receiver consumes messages using Receive, it grants the credits back to the peer.
+// Credit grants travel over the stream itself, so flow control works on top of any
+// transport.Stream whose messages are wrapped in a Message.
+//
+// To add flow control to a transport, wrap it in a Stream. To add flow control to an
+// already open stream, call Client or Server. Both ends of the stream must be flow
+// controlled.
+package flow
+
+import (
+	"context"
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// Unlimited is a credit grant that removes all flow control from the peer.
+const Unlimited = -1
+
+// WindowExceeded is returned by Receive when the peer sends more messages than it was
+// granted credit for.
+var WindowExceeded = errors.New("[transport.flow] - peer exceeded window")
+
+// Message is the message sent over a flow controlled stream.
+type Message[V transport.Message] struct {
+	// Value is the value sent by the peer. Value is only set if Data is true.
+	Value V
+	// Data is true if the message carries a Value.
+	Data bool
+	// Credit is the number of additional messages the peer is allowed to send, or
+	// Unlimited.
+	Credit int
+}
+
+// Stream wraps a transport.Stream to add flow control to every stream it opens and
+// handles.
+type Stream[I, O transport.Message] struct {
+	// Transport is the stream transport to add flow control to.
+	Transport transport.Stream[Message[I], Message[O]]
+	// Window is the number of messages each end of the stream is allowed to send
+	// before the other end receives them. A Window of zero or less disables flow
+	// control.
+	Window int
+}
+
+var _ transport.Stream[any, any] = (*Stream[any, any])(nil)
+
+// Stream implements the transport.Stream interface.
+func (s *Stream[I, O]) Stream(
+	ctx context.Context,
+	target address.Address,
+) (transport.StreamClient[I, O], error) {
+	stream, err := s.Transport.Stream(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return Client[I, O](stream, s.Window)
+}
+
+// Handle implements the transport.Stream interface.
+func (s *Stream[I, O]) Handle(
+	handler func(ctx context.Context, srv transport.StreamServer[I, O]) error,
+) {
+	s.Transport.Handle(func(
+		ctx context.Context,
+		srv transport.StreamServer[Message[I], Message[O]],
+	) error {
+		fSrv, err := Server[I, O](srv, s.Window)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, fSrv)
+	})
+}
+
+// String implements the transport.Stream interface.
+func (s *Stream[I, O]) String() string {
+	return fmt.Sprintf("flow.Stream{window: %d} wrapping %s", s.Window, s.Transport)
+}
+
+// Client adds flow control to a stream opened by a client, granting the server an
+// initial window of credits. The returned client's Send blocks when out of credit
+// until the server receives earlier messages or the stream closes. Because the client
+// can't grant credit after closing its sending direction, CloseSend grants the server
+// Unlimited credit.
+func Client[I, O transport.Message](
+	stream transport.StreamClient[Message[I], Message[O]],
+	window int,
+) (transport.StreamClient[I, O], error) {
+	e, err := newEndpoint[I, O](stream, stream, window)
+	if err != nil {
+		return nil, err
+	}
+	return &client[I, O]{endpoint: e, closer: stream}, nil
+}
+
+// Server adds flow control to a stream handled by a server, granting the client an
+// initial window of credits. The returned server's Send blocks when out of credit
+// until the client receives earlier messages or the stream closes.
+func Server[I, O transport.Message](
+	srv transport.StreamServer[Message[I], Message[O]],
+	window int,
+) (transport.StreamServer[I, O], error) {
+	return newEndpoint[O, I](srv, srv, window)
+}
+
+type client[I, O transport.Message] struct {
+	*endpoint[I, O]
+	closer transport.StreamCloser
+}
+
+// CloseSend implements the transport.StreamCloser interface.
+func (c *client[I, O]) CloseSend() error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.mu.Lock()
+	c.unbounded = true
+	c.mu.Unlock()
+	// If the stream has already failed, the grant fails too, and the reason is
+	// returned by Receive.
+	_ = c.sender.Send(Message[I]{Credit: Unlimited})
+	return c.closer.CloseSend()
+}
+
+// endpoint is one end of a flow controlled stream that sends S and receives R. A
+// background goroutine receives messages from the underlying stream, applying credit
+// grants and buffering values until they're consumed by Receive.
+type endpoint[S, R transport.Message] struct {
+	window int
+	// sendMu serializes sends on the underlying stream, which are made by both Send
+	// and Receive (to grant credit).
+	sendMu   sync.Mutex
+	sender   transport.StreamSender[Message[S]]
+	receiver transport.StreamReceiver[Message[R]]
+	// closed is true once the client has closed its sending direction.
+	closed bool
+	mu     sync.Mutex
+	// unbounded is true if the peer is allowed to send any number of messages.
+	unbounded bool
+	// credit is the number of messages we're allowed to send, or Unlimited.
+	credit int
+	// granted is notified when the peer grants credit.
+	granted chan struct{}
+	// consumed is the number of values consumed since credit was last granted.
+	consumed int
+	values   chan R
+	// done is closed once the underlying stream stops receiving, after which err
+	// holds the error it stopped with.
+	done chan struct{}
+	err  error
+}
+
+func newEndpoint[S, R transport.Message](
+	sender transport.StreamSender[Message[S]],
+	receiver transport.StreamReceiver[Message[R]],
+	window int,
+) (*endpoint[S, R], error) {
+	grant := window
+	if window <= 0 {
+		window, grant = 0, Unlimited
+	}
+	e := &endpoint[S, R]{
+		window:    window,
+		unbounded: window == 0,
+		sender:    sender,
+		receiver:  receiver,
+		granted:   make(chan struct{}, 1),
+		values:    make(chan R, window),
+		done:      make(chan struct{}),
+	}
+	if err := sender.Send(Message[S]{Credit: grant}); err != nil {
+		return nil, err
+	}
+	go e.receive()
+	return e, nil
+}
+
+func (e *endpoint[S, R]) receive() {
+	defer close(e.done)
+	for {
+		msg, err := e.receiver.Receive()
+		if err != nil {
+			e.err = err
+			return
+		}
+		if msg.Credit != 0 {
+			e.grant(msg.Credit)
+		}
+		if !msg.Data {
+			continue
+		}
+		if e.isUnbounded() {
+			// The peer can send any number of values, so we hand them to Receive one
+			// at a time.
+			e.values <- msg.Value
+			continue
+		}
+		select {
+		case e.values <- msg.Value:
+		default:
+			e.err = WindowExceeded
+			return
+		}
+	}
+}
+
+func (e *endpoint[S, R]) isUnbounded() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.unbounded
+}
+
+func (e *endpoint[S, R]) grant(credit int) {
+	e.mu.Lock()
+	if credit == Unlimited || e.credit == Unlimited {
+		e.credit = Unlimited
+	} else {
+		e.credit += credit
+	}
+	e.mu.Unlock()
+	select {
+	case e.granted <- struct{}{}:
+	default:
+	}
+}
+
+// Send implements the transport.StreamSender interface.
+func (e *endpoint[S, R]) Send(v S) error {
+	for !e.acquire() {
+		select {
+		case <-e.granted:
+		case <-e.done:
+			// The peer may have granted credit right before the stream stopped.
+			if !e.acquire() {
+				return e.err
+			}
+			return e.send(v)
+		}
+	}
+	return e.send(v)
+}
+
+func (e *endpoint[S, R]) acquire() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.credit == 0 {
+		return false
+	}
+	if e.credit > 0 {
+		e.credit--
+	}
+	return true
+}
+
+func (e *endpoint[S, R]) send(v S) error {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+	return e.sender.Send(Message[S]{Value: v, Data: true})
+}
+
+// Receive implements the transport.StreamReceiver interface.
+func (e *endpoint[S, R]) Receive() (v R, err error) {
+	select {
+	case v = <-e.values:
+		e.consume()
+		return v, nil
+	default:
+	}
+	select {
+	case v = <-e.values:
+		e.consume()
+		return v, nil
+	case <-e.done:
+		// Values received before the stream stopped come first.
+		select {
+		case v = <-e.values:
+			e.consume()
+			return v, nil
+		default:
+			return v, e.err
+		}
+	}
+}
+
+// consume grants credit back to the peer once half of the window has been consumed,
+// so that grants are batched without stalling the peer.
+func (e *endpoint[S, R]) consume() {
+	if e.window == 0 {
+		return
+	}
+	e.mu.Lock()
+	e.consumed++
+	if e.consumed < (e.window+1)/2 {
+		e.mu.Unlock()
+		return
+	}
+	credit := e.consumed
+	e.consumed = 0
+	e.mu.Unlock()
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+	if e.closed {
+		return
+	}
+	// A failed grant means the stream is broken, and the reason is returned by the
+	// next call to Receive.
+	_ = e.sender.Send(Message[S]{Credit: credit})
+}
@@ -0,0 +1,216 @@
+package flow_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/arya-analytics/x/transport/flow"
+	thttp "github.com/arya-analytics/x/transport/http"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var _ = Describe("Flow", func() {
+	var (
+		net    *tmock.Network[flow.Message[int], flow.Message[int]]
+		client *flow.Stream[int, int]
+		server *flow.Stream[int, int]
+		target *tmock.Stream[flow.Message[int], flow.Message[int]]
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[flow.Message[int], flow.Message[int]]()
+		client = &flow.Stream[int, int]{Transport: net.RouteStream("", 100), Window: 4}
+		target = net.RouteStream("", 100)
+		server = &flow.Stream[int, int]{Transport: target, Window: 4}
+	})
+	It("Should block the client when the server stops receiving", func() {
+		var (
+			proceed  = make(chan struct{})
+			received = make(chan []int, 1)
+			sent     int32
+		)
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			<-proceed
+			var values []int
+			for {
+				v, err := srv.Receive()
+				if errors.Is(err, transport.EOF) {
+					received <- values
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				values = append(values, v)
+			}
+		})
+		stream, err := client.Stream(ctx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < 10; i++ {
+				Expect(stream.Send(i)).To(Succeed())
+				atomic.AddInt32(&sent, 1)
+			}
+			Expect(stream.CloseSend()).To(Succeed())
+		}()
+		Eventually(func() int32 { return atomic.LoadInt32(&sent) }).Should(Equal(int32(4)))
+		Consistently(func() int32 { return atomic.LoadInt32(&sent) }, 10*time.Millisecond).Should(Equal(int32(4)))
+		close(proceed)
+		Eventually(received).Should(Receive(Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})))
+		_, err = stream.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+	It("Should block the server when the client stops receiving", func() {
+		var sent int32
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			for i := 0; i < 10; i++ {
+				if err := srv.Send(i); err != nil {
+					return err
+				}
+				atomic.AddInt32(&sent, 1)
+			}
+			return nil
+		})
+		stream, err := client.Stream(ctx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() int32 { return atomic.LoadInt32(&sent) }).Should(Equal(int32(4)))
+		Consistently(func() int32 { return atomic.LoadInt32(&sent) }, 10*time.Millisecond).Should(Equal(int32(4)))
+		for i := 0; i < 10; i++ {
+			v, err := stream.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(i))
+		}
+		_, err = stream.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+	It("Should grant the server unlimited credit when the client closes", func() {
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			if _, err := srv.Receive(); !errors.Is(err, transport.EOF) {
+				return err
+			}
+			for i := 0; i < 10; i++ {
+				if err := srv.Send(i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		stream, err := client.Stream(ctx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stream.CloseSend()).To(Succeed())
+		Eventually(func() error { return net.StreamHistory()[0].Error }).Should(MatchError(transport.EOF))
+		for i := 0; i < 10; i++ {
+			v, err := stream.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(i))
+		}
+	})
+	It("Should unblock a sender when the stream is cancelled", func() {
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		cCtx, cancel := context.WithCancel(ctx)
+		stream, err := client.Stream(cCtx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 4; i++ {
+			Expect(stream.Send(i)).To(Succeed())
+		}
+		errs := make(chan error)
+		go func() { errs <- stream.Send(4) }()
+		Consistently(errs, 5*time.Millisecond).ShouldNot(Receive())
+		cancel()
+		Eventually(errs).Should(Receive(MatchError(context.Canceled)))
+	})
+	It("Should return an error when the peer exceeds its window", func() {
+		raw := net.RouteStream("", 100)
+		proceed := make(chan struct{})
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			<-proceed
+			for {
+				if _, err := srv.Receive(); err != nil {
+					return err
+				}
+			}
+		})
+		stream, err := raw.Stream(ctx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		grant, err := stream.Receive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(grant.Credit).To(Equal(4))
+		for i := 0; i < 5; i++ {
+			Expect(stream.Send(flow.Message[int]{Value: i, Data: true})).To(Succeed())
+		}
+		// Give the server time to receive the messages before the handler starts
+		// consuming them.
+		time.Sleep(10 * time.Millisecond)
+		close(proceed)
+		Eventually(func() error { return net.StreamHistory()[0].Error }).Should(HaveOccurred())
+		Expect(net.StreamHistory()[0].Error).To(MatchError(flow.WindowExceeded))
+	})
+	It("Should not apply flow control with a window of zero", func() {
+		client.Window, server.Window = 0, 0
+		server.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			for i := 0; i < 10; i++ {
+				if err := srv.Send(i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		stream, err := client.Stream(ctx, target.Address)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() error { return net.StreamHistory()[0].Error }).Should(MatchError(transport.EOF))
+		for i := 0; i < 10; i++ {
+			v, err := stream.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(i))
+		}
+	})
+})
+
+var _ = Describe("Flow over HTTP", func() {
+	It("Should exchange flow controlled messages over a WebSocket", func() {
+		t := thttp.NewStream[flow.Message[int], flow.Message[int]]("/flow")
+		srv := httptest.NewServer(t)
+		defer srv.Close()
+		s := &flow.Stream[int, int]{Transport: t, Window: 2}
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			for {
+				v, err := srv.Receive()
+				if errors.Is(err, transport.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if err := srv.Send(v * 2); err != nil {
+					return err
+				}
+			}
+		})
+		stream, err := s.Stream(ctx, address.Address(strings.TrimPrefix(srv.URL, "http://")))
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < 20; i++ {
+				Expect(stream.Send(i)).To(Succeed())
+			}
+			Expect(stream.CloseSend()).To(Succeed())
+		}()
+		for i := 0; i < 20; i++ {
+			v, err := stream.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v).To(Equal(i * 2))
+		}
+		_, err = stream.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+})
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec encodes and decodes the messages exchanged by a transport.
+type Codec interface {
+	// ContentType is the MIME type of encoded messages.
+	ContentType() string
+	// Encode encodes the provided value.
+	Encode(value interface{}) ([]byte, error)
+	// Decode decodes the data into the provided pointer.
+	Decode(data []byte, value interface{}) error
+}
+
+// JSONCodec is a Codec that encodes messages as JSON. JSONCodec is the default
+// Codec, as it can be used by most non-Go clients.
+type JSONCodec struct{}
+
+// ContentType implements Codec.
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// Encode implements Codec.
+func (JSONCodec) Encode(value interface{}) ([]byte, error) { return json.Marshal(value) }
+
+// Decode implements Codec.
+func (JSONCodec) Decode(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// GobCodec is a Codec that encodes messages using encoding/gob. GobCodec should
+// only be used when both the client and server are written in Go.
+type GobCodec struct{}
+
+// ContentType implements Codec.
+func (GobCodec) ContentType() string { return "application/x-gob" }
+
+// Encode implements Codec.
+func (GobCodec) Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(value)
+	return buf.Bytes(), err
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
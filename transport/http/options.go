@@ -0,0 +1,73 @@
+package http
+
+import (
+	"crypto/tls"
+	"golang.org/x/net/websocket"
+	"net/http"
+)
+
+type options struct {
+	codec          Codec
+	client         *http.Client
+	tls            *tls.Config
+	maxMessageSize int
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	mergeDefaultOptions(o)
+	return o
+}
+
+func mergeDefaultOptions(o *options) {
+	if o.codec == nil {
+		o.codec = JSONCodec{}
+	}
+	if o.client == nil {
+		o.client = http.DefaultClient
+		if o.tls != nil {
+			o.client = &http.Client{Transport: &http.Transport{TLSClientConfig: o.tls}}
+		}
+	}
+	if o.maxMessageSize <= 0 {
+		o.maxMessageSize = websocket.DefaultMaxPayloadBytes
+	}
+}
+
+// scheme returns the scheme of the URLs requests are sent to.
+func (o *options) scheme(secure, insecure string) string {
+	if o.tls != nil {
+		return secure
+	}
+	return insecure
+}
+
+// WithCodec sets the Codec used to encode messages. Defaults to JSONCodec. The client
+// and server must use the same Codec.
+func WithCodec(codec Codec) Option {
+	return func(o *options) { o.codec = codec }
+}
+
+// WithClient sets the http.Client used to send unary requests. Defaults to
+// http.DefaultClient, or to a client using the configuration provided to WithTLS.
+func WithClient(client *http.Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithTLS sends requests over HTTPS and opens streams over secure WebSockets, using
+// the provided configuration to dial the server.
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) { o.tls = cfg }
+}
+
+// WithMaxMessageSize sets the maximum size in bytes of an encoded message the server
+// accepts, either as the body of a unary request or as a stream frame. Larger
+// unary requests are rejected, and larger frames break the stream. Defaults to 32MB.
+func WithMaxMessageSize(size int) Option {
+	return func(o *options) { o.maxMessageSize = size }
+}
@@ -0,0 +1,425 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/net/websocket"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// frameType is the first byte of every WebSocket frame sent by a Stream.
+type frameType byte
+
+const (
+	// frameMessage carries a message encoded using the Stream's Codec.
+	frameMessage frameType = iota + 1
+	// frameClose is sent by the client when it calls CloseSend.
+	frameClose
+	// frameEOF is sent by the server when its handler exits without error.
+	frameEOF
+	// frameError is sent by the server when its handler exits with an error. The
+	// payload is the error message.
+	frameError
+)
+
+type frame struct {
+	variant frameType
+	payload []byte
+}
+
+func writeFrame(ws *websocket.Conn, f frame) error {
+	return websocket.Message.Send(ws, append([]byte{byte(f.variant)}, f.payload...))
+}
+
+func readFrame(ws *websocket.Conn) (frame, error) {
+	var b []byte
+	if err := websocket.Message.Receive(ws, &b); err != nil {
+		return frame{}, err
+	}
+	if len(b) == 0 {
+		return frame{}, errors.New("[x.transport.http] - received empty frame")
+	}
+	return frame{variant: frameType(b[0]), payload: b[1:]}, nil
+}
+
+// unexpectedClose is returned when the underlying connection closes before the
+// stream is closed.
+var unexpectedClose = errors.Wrap(io.ErrUnexpectedEOF, "[x.transport.http] - connection closed")
+
+// Stream is an implementation of transport.Stream over a WebSocket.
+type Stream[I, O transport.Message] struct {
+	// Path is the path the Stream is served at.
+	Path string
+	*options
+	handler func(ctx context.Context, srv transport.StreamServer[I, O]) error
+}
+
+var _ transport.Stream[any, any] = (*Stream[any, any])(nil)
+
+// NewStream returns a new Stream served at the provided path. To handle streams, call
+// Handle and mount the Stream on a server at the same path.
+func NewStream[I, O transport.Message](path string, opts ...Option) *Stream[I, O] {
+	return &Stream[I, O]{Path: path, options: newOptions(opts...)}
+}
+
+// Stream implements the transport.Stream interface. The stream's resources are
+// released once the server closes the stream or the context is cancelled, so callers
+// that abandon a stream before the server closes it should cancel its context.
+func (s *Stream[I, O]) Stream(
+	ctx context.Context,
+	target address.Address,
+) (transport.StreamClient[I, O], error) {
+	cfg, err := websocket.NewConfig(
+		s.scheme("wss", "ws")+"://"+string(target)+s.Path,
+		s.scheme("https", "http")+"://"+string(target),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.dial(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	ws, err := websocket.NewClient(cfg, conn)
+	if err != nil {
+		return nil, errors.CombineErrors(err, conn.Close())
+	}
+	_ = conn.SetDeadline(time.Time{})
+	c := &clientStream[I, O]{
+		codec:     s.codec,
+		ctx:       ctx,
+		ws:        ws,
+		responses: make(chan frame),
+		closed:    make(chan struct{}),
+	}
+	go c.receive()
+	return c, nil
+}
+
+func (s *Stream[I, O]) dial(ctx context.Context, target address.Address) (net.Conn, error) {
+	if s.tls != nil {
+		return (&tls.Dialer{Config: s.tls}).DialContext(ctx, "tcp", string(target))
+	}
+	return (&net.Dialer{}).DialContext(ctx, "tcp", string(target))
+}
+
+// Handle implements the transport.Stream interface.
+func (s *Stream[I, O]) Handle(handler func(ctx context.Context, srv transport.StreamServer[I, O]) error) {
+	s.handler = handler
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Stream[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.handler == nil {
+		http.Error(w, "[x.transport.http] - no handler registered", http.StatusNotFound)
+		return
+	}
+	websocket.Server{Handler: s.serve}.ServeHTTP(w, r)
+}
+
+// String implements the transport.Stream interface.
+func (s *Stream[I, O]) String() string {
+	return fmt.Sprintf("http.Stream{} at %s", s.Path)
+}
+
+func (s *Stream[I, O]) serve(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = s.maxMessageSize
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	srv := &serverStream[I, O]{codec: s.codec, ctx: ctx, ws: ws, requests: make(chan frame)}
+	// The context is cancelled if the connection breaks. Frames received after the
+	// client closes the stream are discarded.
+	go func() {
+		defer cancel()
+		closed := false
+		for {
+			f, err := readFrame(ws)
+			if err != nil {
+				return
+			}
+			if closed {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case srv.requests <- f:
+			}
+			closed = f.variant == frameClose
+		}
+	}()
+	err := s.handler(ctx, srv)
+	closing := frame{variant: frameEOF}
+	if err != nil {
+		closing = frame{variant: frameError, payload: []byte(err.Error())}
+	}
+	_ = writeFrame(ws, closing)
+	_ = ws.Close()
+}
+
+type serverStream[I, O transport.Message] struct {
+	codec    Codec
+	ctx      context.Context
+	ws       *websocket.Conn
+	requests chan frame
+	// mu guards inboundFatalErr and outboundFatalErr, so that Send can be called
+	// concurrently with Receive.
+	mu sync.Mutex
+	// inboundFatalErr is the error the inbound direction of the stream closed with.
+	inboundFatalErr  error
+	outboundFatalErr error
+}
+
+// Send implements the transport.StreamSender interface.
+func (s *serverStream[I, O]) Send(res O) error {
+	s.mu.Lock()
+	if s.outboundFatalErr == nil && s.ctx.Err() != nil {
+		s.outboundFatalErr = s.ctx.Err()
+	}
+	err := s.outboundFatalErr
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	b, err := s.codec.Encode(res)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(s.ws, frame{variant: frameMessage, payload: b}); err != nil {
+		return s.failOutbound(err)
+	}
+	return nil
+}
+
+// Receive implements the transport.StreamReceiver interface.
+func (s *serverStream[I, O]) Receive() (req I, err error) {
+	if err := s.inboundErr(); err != nil {
+		return req, err
+	}
+	select {
+	case <-s.ctx.Done():
+		return req, s.failInbound(s.ctx.Err())
+	case f := <-s.requests:
+		if f.variant == frameClose {
+			return req, s.failInbound(transport.EOF)
+		}
+		return req, s.codec.Decode(f.payload, &req)
+	}
+}
+
+func (s *serverStream[I, O]) inboundErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inboundFatalErr
+}
+
+// failInbound closes the inbound direction of the stream with err, unless it's
+// already closed, and returns the error it's closed with.
+func (s *serverStream[I, O]) failInbound(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inboundFatalErr == nil {
+		s.inboundFatalErr = err
+	}
+	return s.inboundFatalErr
+}
+
+// failOutbound closes the outbound direction of the stream with err, unless it's
+// already closed, and returns the error it's closed with.
+func (s *serverStream[I, O]) failOutbound(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outboundFatalErr == nil {
+		s.outboundFatalErr = err
+	}
+	return s.outboundFatalErr
+}
+
+type clientStream[I, O transport.Message] struct {
+	codec     Codec
+	ctx       context.Context
+	ws        *websocket.Conn
+	responses chan frame
+	// closed is closed once the server closes the stream or the connection breaks.
+	closed chan struct{}
+	// closing is the frame the server closed the stream with, if any. It's set
+	// before closed is closed, and must only be read afterwards.
+	closing frame
+	// closeOnce ensures the connection is only closed once.
+	closeOnce sync.Once
+	// mu guards inboundFatalErr and outboundFatalErr, which are set by both Send and
+	// Receive (when the server closes the stream), so that they can be called
+	// concurrently.
+	mu sync.Mutex
+	// inboundFatalErr is the error the inbound direction of the stream closed with.
+	inboundFatalErr  error
+	outboundFatalErr error
+}
+
+// receive reads frames from the server until the stream closes, and closes the
+// connection when the context is cancelled. The frame the server closes the stream
+// with is held in closing instead of being sent on responses, so receive exits once
+// the server closes the stream, even if Receive isn't called.
+func (c *clientStream[I, O]) receive() {
+	defer close(c.closed)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			c.closeConn()
+		case <-done:
+		}
+	}()
+	for {
+		f, err := readFrame(c.ws)
+		if err != nil {
+			c.closeConn()
+			return
+		}
+		if f.variant != frameMessage {
+			c.closing = f
+			c.closeConn()
+			return
+		}
+		select {
+		case <-c.ctx.Done():
+			return
+		case c.responses <- f:
+		}
+	}
+}
+
+func (c *clientStream[I, O]) closeConn() { c.closeOnce.Do(func() { _ = c.ws.Close() }) }
+
+// Send implements the transport.StreamSender interface.
+func (c *clientStream[I, O]) Send(req I) error {
+	c.mu.Lock()
+	if c.outboundFatalErr == nil && c.ctx.Err() != nil {
+		c.outboundFatalErr = c.ctx.Err()
+	}
+	if c.outboundFatalErr == nil {
+		select {
+		case <-c.closed:
+			c.outboundFatalErr = transport.EOF
+		default:
+		}
+	}
+	err := c.outboundFatalErr
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	b, err := c.codec.Encode(req)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(c.ws, frame{variant: frameMessage, payload: b}); err != nil {
+		if c.ctx.Err() != nil {
+			err = c.ctx.Err()
+		} else {
+			err = transport.EOF
+		}
+		return c.failOutbound(err)
+	}
+	return nil
+}
+
+// Receive implements the transport.StreamReceiver interface.
+func (c *clientStream[I, O]) Receive() (res O, err error) {
+	if err := c.inboundErr(); err != nil {
+		return res, err
+	}
+	select {
+	case <-c.ctx.Done():
+		return res, c.failInbound(c.ctx.Err())
+	case <-c.closed:
+		// Messages are handed off before the stream closes, so the closing frame
+		// is the last one the server sent.
+		if c.closing.variant != 0 {
+			return c.handle(c.closing)
+		}
+		// The connection is closed when the context is cancelled, so we need to
+		// check the context to return the right error.
+		if err := c.ctx.Err(); err != nil {
+			return res, c.failInbound(err)
+		}
+		return res, c.failInbound(unexpectedClose)
+	case f := <-c.responses:
+		return c.handle(f)
+	}
+}
+
+func (c *clientStream[I, O]) handle(f frame) (res O, err error) {
+	switch f.variant {
+	case frameMessage:
+		return res, c.codec.Decode(f.payload, &res)
+	case frameEOF:
+		err = transport.EOF
+	case frameError:
+		err = errors.New(string(f.payload))
+	default:
+		err = errors.Newf("[x.transport.http] - unexpected frame type %d", f.variant)
+	}
+	// The server has closed the stream, so there's no need to notify it.
+	_ = c.failOutbound(transport.EOF)
+	return res, c.failInbound(err)
+}
+
+// CloseSend implements the transport.StreamCloser interface. If the connection has
+// already broken, CloseSend does nothing, and the reason is returned by the next call
+// to Receive.
+func (c *clientStream[I, O]) CloseSend() error {
+	c.mu.Lock()
+	if c.outboundFatalErr != nil {
+		c.mu.Unlock()
+		return nil
+	}
+	c.outboundFatalErr = transport.EOF
+	c.mu.Unlock()
+	select {
+	case <-c.closed:
+	default:
+		_ = writeFrame(c.ws, frame{variant: frameClose})
+	}
+	return nil
+}
+
+func (c *clientStream[I, O]) inboundErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inboundFatalErr
+}
+
+// failInbound closes the inbound direction of the stream with err, unless it's
+// already closed, and returns the error it's closed with.
+func (c *clientStream[I, O]) failInbound(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inboundFatalErr == nil {
+		c.inboundFatalErr = err
+	}
+	return c.inboundFatalErr
+}
+
+// failOutbound closes the outbound direction of the stream with err, unless it's
+// already closed, and returns the error it's closed with.
+func (c *clientStream[I, O]) failOutbound(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.outboundFatalErr == nil {
+		c.outboundFatalErr = err
+	}
+	return c.outboundFatalErr
+}
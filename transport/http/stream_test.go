@@ -0,0 +1,201 @@
+package http_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	thttp "github.com/arya-analytics/x/transport/http"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+)
+
+var _ = Describe("Stream", func() {
+	var (
+		s    *thttp.Stream[request, response]
+		srv  *httptest.Server
+		addr address.Address
+	)
+	BeforeEach(func() {
+		s = thttp.NewStream[request, response]("/stream")
+		mux := http.NewServeMux()
+		mux.Handle(s.Path, s)
+		srv, addr = serve(mux)
+	})
+	AfterEach(func() { srv.Close() })
+	It("Should exchange messages until the client closes the stream", func() {
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			for {
+				req, err := srv.Receive()
+				if errors.Is(err, transport.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if err := srv.Send(response{Value: req.Value + 1}); err != nil {
+					return err
+				}
+			}
+		})
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 1; i <= 3; i++ {
+			Expect(client.Send(request{Value: i})).To(Succeed())
+		}
+		Expect(client.CloseSend()).To(Succeed())
+		for i := 1; i <= 3; i++ {
+			res, err := client.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Value).To(Equal(i + 1))
+		}
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+	It("Should allow sending and receiving concurrently", func() {
+		const n = 50
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			received := make(chan error, 1)
+			go func() {
+				for {
+					if _, err := srv.Receive(); err != nil {
+						received <- err
+						return
+					}
+				}
+			}()
+			for i := 0; i < n; i++ {
+				if err := srv.Send(response{Value: i}); err != nil {
+					return err
+				}
+			}
+			// Close the stream while the client is still sending.
+			return nil
+		})
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		sent := make(chan error, 1)
+		go func() {
+			for i := 0; ; i++ {
+				if err := client.Send(request{Value: i}); err != nil {
+					sent <- err
+					return
+				}
+			}
+		}()
+		for i := 0; i < n; i++ {
+			res, err := client.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Value).To(Equal(i))
+		}
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+		Eventually(sent).Should(Receive(MatchError(transport.EOF)))
+		Expect(client.CloseSend()).To(Succeed())
+	})
+	It("Should return the error the server handler exits with", func() {
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			_, err := srv.Receive()
+			Expect(err).ToNot(HaveOccurred())
+			return errors.New("handler failed")
+		})
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Send(request{Value: 1})).To(Succeed())
+		_, err = client.Receive()
+		Expect(err).To(MatchError("handler failed"))
+		Expect(client.Send(request{Value: 2})).To(MatchError(transport.EOF))
+		Expect(client.CloseSend()).To(Succeed())
+	})
+	It("Should return a transport.EOF when the server handler exits", func() {
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			return nil
+		})
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+		Expect(client.Send(request{Value: 1})).To(MatchError(transport.EOF))
+	})
+	It("Should cancel the server context when the client context is cancelled", func() {
+		serverErr := make(chan error, 1)
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			_, err := srv.Receive()
+			serverErr <- err
+			return err
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		client, err := s.Stream(ctx, addr)
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+		Eventually(serverErr).Should(Receive(MatchError(context.Canceled)))
+		Expect(client.Send(request{Value: 1})).To(MatchError(context.Canceled))
+		_, err = client.Receive()
+		Expect(err).To(MatchError(context.Canceled))
+	})
+	It("Should release the stream once the server closes it", func() {
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			_, err := srv.Receive()
+			Expect(err).To(MatchError(transport.EOF))
+			return nil
+		})
+		goroutines := runtime.NumGoroutine()
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.CloseSend()).To(Succeed())
+		Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", goroutines))
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+	It("Should open streams over TLS", func() {
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			req, err := srv.Receive()
+			if err != nil {
+				return err
+			}
+			return srv.Send(response{Value: req.Value + 1})
+		})
+		mux := http.NewServeMux()
+		mux.Handle(s.Path, s)
+		tlsSrv := httptest.NewTLSServer(mux)
+		defer tlsSrv.Close()
+		tlsStream := thttp.NewStream[request, response](
+			s.Path,
+			thttp.WithTLS(tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig),
+		)
+		client, err := tlsStream.Stream(
+			context.Background(),
+			address.Address(strings.TrimPrefix(tlsSrv.URL, "https://")),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Send(request{Value: 1})).To(Succeed())
+		res, err := client.Receive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Value).To(Equal(2))
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+	})
+	It("Should break the stream when a frame exceeds the maximum message size", func() {
+		serverErr := make(chan error, 1)
+		s = thttp.NewStream[request, response]("/stream", thttp.WithMaxMessageSize(8))
+		s.Handle(func(ctx context.Context, srv transport.StreamServer[request, response]) error {
+			_, err := srv.Receive()
+			serverErr <- err
+			return err
+		})
+		mux := http.NewServeMux()
+		mux.Handle(s.Path, s)
+		srv, addr := serve(mux)
+		defer srv.Close()
+		client, err := s.Stream(context.Background(), addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Send(request{Value: 123456789})).To(Succeed())
+		Eventually(serverErr).Should(Receive(HaveOccurred()))
+	})
+})
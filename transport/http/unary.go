@@ -0,0 +1,128 @@
+// Package http implements transport.Unary and transport.Stream over HTTP, so that
+// they can be used by browsers and other clients that can't speak gRPC.
+//
+// Unary requests are sent as HTTP POST requests whose body is the request encoded
+// using a Codec. Streams are carried over a WebSocket, where each message is sent as a
+// single binary frame. Both transports implement http.Handler on the server side, and
+// should be mounted on an http.ServeMux (or any other router) at their Path.
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Unary is an implementation of transport.Unary over HTTP POST requests.
+type Unary[I, O transport.Message] struct {
+	// Path is the path the Unary is served at.
+	Path string
+	*options
+	handler func(context.Context, I) (O, error)
+}
+
+var _ transport.Unary[any, any] = (*Unary[any, any])(nil)
+
+// NewUnary returns a new Unary served at the provided path. To handle requests, call
+// Handle and mount the Unary on a server at the same path.
+func NewUnary[I, O transport.Message](path string, opts ...Option) *Unary[I, O] {
+	return &Unary[I, O]{Path: path, options: newOptions(opts...)}
+}
+
+// Send implements the transport.Unary interface. The request is sent to the Unary's
+// Path on the target host. If the server's handler returns an error, Send returns an
+// error with the same message.
+func (u *Unary[I, O]) Send(ctx context.Context, target address.Address, req I) (res O, err error) {
+	b, err := u.codec.Encode(req)
+	if err != nil {
+		return res, err
+	}
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		u.scheme("https", "http")+"://"+string(target)+u.Path,
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return res, err
+	}
+	httpReq.Header.Set("Content-Type", u.codec.ContentType())
+	httpRes, err := u.client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		return res, err
+	}
+	defer func() { err = errors.CombineErrors(err, httpRes.Body.Close()) }()
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return res, err
+	}
+	if httpRes.StatusCode != http.StatusOK {
+		return res, decodeError(httpRes.StatusCode, body)
+	}
+	return res, u.codec.Decode(body, &res)
+}
+
+// Handle implements the transport.Unary interface.
+func (u *Unary[I, O]) Handle(handler func(context.Context, I) (O, error)) {
+	u.handler = handler
+}
+
+// ServeHTTP implements http.Handler.
+func (u *Unary[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "[x.transport.http] - method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if u.handler == nil {
+		http.Error(w, "[x.transport.http] - no handler registered", http.StatusNotFound)
+		return
+	}
+	if r.ContentLength > int64(u.maxMessageSize) {
+		http.Error(w, "[x.transport.http] - request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(u.maxMessageSize)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req I
+	if err := u.codec.Decode(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, err := u.handler(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b, err := u.codec.Encode(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", u.codec.ContentType())
+	_, _ = w.Write(b)
+}
+
+// String implements the transport.Unary interface.
+func (u *Unary[I, O]) String() string {
+	return fmt.Sprintf("http.Unary{} at %s", u.Path)
+}
+
+func decodeError(status int, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	if status == http.StatusInternalServerError {
+		return errors.New(msg)
+	}
+	return errors.Newf("[x.transport.http] - %s: %s", http.StatusText(status), msg)
+}
@@ -0,0 +1,117 @@
+package http_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	thttp "github.com/arya-analytics/x/transport/http"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+type request struct {
+	Value int `json:"value"`
+}
+
+type response struct {
+	Value int `json:"value"`
+}
+
+func serve(mux *http.ServeMux) (*httptest.Server, address.Address) {
+	srv := httptest.NewServer(mux)
+	return srv, address.Address(strings.TrimPrefix(srv.URL, "http://"))
+}
+
+var _ = Describe("Unary", func() {
+	for _, codec := range []thttp.Codec{thttp.JSONCodec{}, thttp.GobCodec{}} {
+		codec := codec
+		Describe(codec.ContentType(), func() {
+			var (
+				u    *thttp.Unary[request, response]
+				srv  *httptest.Server
+				addr address.Address
+			)
+			BeforeEach(func() {
+				u = thttp.NewUnary[request, response]("/unary", thttp.WithCodec(codec))
+				mux := http.NewServeMux()
+				mux.Handle(u.Path, u)
+				srv, addr = serve(mux)
+			})
+			AfterEach(func() { srv.Close() })
+			It("Should exchange a request and response", func() {
+				u.Handle(func(ctx context.Context, req request) (response, error) {
+					return response{Value: req.Value + 1}, nil
+				})
+				res, err := u.Send(context.Background(), addr, request{Value: 1})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.Value).To(Equal(2))
+			})
+			It("Should return the error returned by the handler", func() {
+				u.Handle(func(ctx context.Context, req request) (response, error) {
+					return response{}, errors.New("handler failed")
+				})
+				_, err := u.Send(context.Background(), addr, request{Value: 1})
+				Expect(err).To(MatchError("handler failed"))
+			})
+			It("Should return a context error when the context is cancelled", func() {
+				u.Handle(func(ctx context.Context, req request) (response, error) {
+					<-ctx.Done()
+					return response{}, ctx.Err()
+				})
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				_, err := u.Send(ctx, addr, request{Value: 1})
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+			})
+		})
+	}
+	It("Should reject requests larger than the maximum message size", func() {
+		u := thttp.NewUnary[request, response]("/unary", thttp.WithMaxMessageSize(16))
+		u.Handle(func(ctx context.Context, req request) (response, error) {
+			return response{Value: req.Value}, nil
+		})
+		mux := http.NewServeMux()
+		mux.Handle(u.Path, u)
+		srv, addr := serve(mux)
+		defer srv.Close()
+		_, err := u.Send(context.Background(), addr, request{Value: 123456789})
+		Expect(err).To(MatchError(ContainSubstring(
+			http.StatusText(http.StatusRequestEntityTooLarge),
+		)))
+		res, err := u.Send(context.Background(), addr, request{Value: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Value).To(Equal(1))
+	})
+	It("Should send requests over TLS", func() {
+		u := thttp.NewUnary[request, response]("/unary")
+		u.Handle(func(ctx context.Context, req request) (response, error) {
+			return response{Value: req.Value + 1}, nil
+		})
+		mux := http.NewServeMux()
+		mux.Handle(u.Path, u)
+		srv := httptest.NewTLSServer(mux)
+		defer srv.Close()
+		client := thttp.NewUnary[request, response](
+			u.Path,
+			thttp.WithTLS(srv.Client().Transport.(*http.Transport).TLSClientConfig),
+		)
+		res, err := client.Send(
+			context.Background(),
+			address.Address(strings.TrimPrefix(srv.URL, "https://")),
+			request{Value: 1},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Value).To(Equal(2))
+	})
+	It("Should return an error when no handler is registered", func() {
+		u := thttp.NewUnary[request, response]("/unary")
+		srv, addr := serve(http.NewServeMux())
+		defer srv.Close()
+		_, err := u.Send(context.Background(), addr, request{Value: 1})
+		Expect(err).To(HaveOccurred())
+	})
+})
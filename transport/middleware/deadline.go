@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"time"
+)
+
+// Deadline returns Middleware that propagates the deadline of a unary request's
+// context from the client to the server. As transports don't carry request metadata,
+// the deadline is attached to the request itself: on the client side, the deadline
+// of the context passed to Send is attached using set, and on the server side, the
+// handler's context is given the deadline returned by get. If get returns false, the
+// handler's context is left untouched.
+//
+// If defaultTimeout is positive, it's applied to client requests whose context has
+// no deadline.
+func Deadline[I, O transport.Message](
+	set func(req I, deadline time.Time) I,
+	get func(req I) (time.Time, bool),
+	defaultTimeout time.Duration,
+) Middleware[I, O] {
+	return Middleware[I, O]{
+		UnaryClient: func(
+			ctx context.Context,
+			target address.Address,
+			req I,
+			next UnaryClientHandler[I, O],
+		) (O, error) {
+			if _, ok := ctx.Deadline(); !ok && defaultTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+				defer cancel()
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				req = set(req, deadline)
+			}
+			return next(ctx, target, req)
+		},
+		UnaryServer: func(ctx context.Context, req I, next UnaryServerHandler[I, O]) (O, error) {
+			if deadline, ok := get(req); ok {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, deadline)
+				defer cancel()
+			}
+			return next(ctx, req)
+		},
+	}
+}
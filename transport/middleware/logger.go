@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Logger returns Middleware that logs every unary request and stream to the provided
+// logger. Successful calls are logged at the debug level, and failed calls are logged
+// at the error level. Streams that close with transport.EOF or a context error are
+// considered successful.
+func Logger[I, O transport.Message](logger *zap.Logger) Middleware[I, O] {
+	return Middleware[I, O]{
+		UnaryClient: func(
+			ctx context.Context,
+			target address.Address,
+			req I,
+			next UnaryClientHandler[I, O],
+		) (O, error) {
+			start := time.Now()
+			res, err := next(ctx, target, req)
+			log(logger, "unary request sent", err,
+				zap.Stringer("target", target),
+				zap.Duration("duration", time.Since(start)),
+			)
+			return res, err
+		},
+		UnaryServer: func(ctx context.Context, req I, next UnaryServerHandler[I, O]) (O, error) {
+			start := time.Now()
+			res, err := next(ctx, req)
+			log(logger, "unary request handled", err, zap.Duration("duration", time.Since(start)))
+			return res, err
+		},
+		StreamClient: func(
+			ctx context.Context,
+			target address.Address,
+			next StreamClientHandler[I, O],
+		) (transport.StreamClient[I, O], error) {
+			client, err := next(ctx, target)
+			log(logger, "stream opened", err, zap.Stringer("target", target))
+			return client, err
+		},
+		StreamServer: func(
+			ctx context.Context,
+			srv transport.StreamServer[I, O],
+			next StreamServerHandler[I, O],
+		) error {
+			start := time.Now()
+			err := next(ctx, srv)
+			log(logger, "stream closed", err, zap.Duration("duration", time.Since(start)))
+			return err
+		},
+	}
+}
+
+func log(logger *zap.Logger, msg string, err error, fields ...zap.Field) {
+	if err == nil ||
+		errors.Is(err, transport.EOF) ||
+		errors.Is(err, context.Canceled) {
+		logger.Debug(msg, fields...)
+		return
+	}
+	logger.Error(msg, append(fields, zap.Error(err))...)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/alamos"
+	"github.com/arya-analytics/x/transport"
+)
+
+// TransportMetrics are the metrics recorded by the Middleware returned by Metrics.
+type TransportMetrics struct {
+	// UnaryClient tracks the number of unary requests sent and their average latency.
+	UnaryClient alamos.Duration
+	// UnaryServer tracks the number of unary requests handled and their average
+	// latency.
+	UnaryServer alamos.Duration
+	// StreamClient tracks the number of streams opened and the average time taken to
+	// open them.
+	StreamClient alamos.Duration
+	// StreamServer tracks the number of streams handled and their average lifetime.
+	StreamServer alamos.Duration
+}
+
+// Metrics returns Middleware that records latency metrics under a "transport"
+// sub-experiment of the provided experiment, along with the recorded metrics. The
+// returned Middleware should only be created once per experiment.
+func Metrics[I, O transport.Message](exp alamos.Experiment) (Middleware[I, O], TransportMetrics) {
+	subExp := alamos.Sub(exp, "transport")
+	m := TransportMetrics{
+		UnaryClient:  alamos.NewGaugeDuration(subExp, alamos.Debug, "unary.client"),
+		UnaryServer:  alamos.NewGaugeDuration(subExp, alamos.Debug, "unary.server"),
+		StreamClient: alamos.NewGaugeDuration(subExp, alamos.Debug, "stream.client"),
+		StreamServer: alamos.NewGaugeDuration(subExp, alamos.Debug, "stream.server"),
+	}
+	return Middleware[I, O]{
+		UnaryClient: func(
+			ctx context.Context,
+			target address.Address,
+			req I,
+			next UnaryClientHandler[I, O],
+		) (O, error) {
+			sw := m.UnaryClient.Stopwatch()
+			sw.Start()
+			defer sw.Stop()
+			return next(ctx, target, req)
+		},
+		UnaryServer: func(ctx context.Context, req I, next UnaryServerHandler[I, O]) (O, error) {
+			sw := m.UnaryServer.Stopwatch()
+			sw.Start()
+			defer sw.Stop()
+			return next(ctx, req)
+		},
+		StreamClient: func(
+			ctx context.Context,
+			target address.Address,
+			next StreamClientHandler[I, O],
+		) (transport.StreamClient[I, O], error) {
+			sw := m.StreamClient.Stopwatch()
+			sw.Start()
+			defer sw.Stop()
+			return next(ctx, target)
+		},
+		StreamServer: func(
+			ctx context.Context,
+			srv transport.StreamServer[I, O],
+			next StreamServerHandler[I, O],
+		) error {
+			sw := m.StreamServer.Stopwatch()
+			sw.Start()
+			defer sw.Stop()
+			return next(ctx, srv)
+		},
+	}, m
+}
@@ -0,0 +1,167 @@
+// Package middleware implements composable interceptor chains for transport.Unary and
+// transport.Stream. Interceptors can be applied on the client side (i.e. around
+// Send and Stream) and on the server side (i.e. around the handler passed to Handle).
+// To apply a chain of Middleware to a transport, call WrapUnary or WrapStream.
+//
+// The package ships built-in Middleware for logging (Logger), latency metrics
+// (Metrics), and deadline propagation (Deadline).
+package middleware
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+)
+
+// UnaryClientHandler sends a unary request to the target.
+type UnaryClientHandler[I, O transport.Message] func(
+	ctx context.Context,
+	target address.Address,
+	req I,
+) (O, error)
+
+// UnaryClientInterceptor intercepts a unary request sent by a client. It must call
+// next to continue the chain.
+type UnaryClientInterceptor[I, O transport.Message] func(
+	ctx context.Context,
+	target address.Address,
+	req I,
+	next UnaryClientHandler[I, O],
+) (O, error)
+
+// UnaryServerHandler handles a unary request received by a server.
+type UnaryServerHandler[I, O transport.Message] func(ctx context.Context, req I) (O, error)
+
+// UnaryServerInterceptor intercepts a unary request received by a server. It must call
+// next to continue the chain.
+type UnaryServerInterceptor[I, O transport.Message] func(
+	ctx context.Context,
+	req I,
+	next UnaryServerHandler[I, O],
+) (O, error)
+
+// StreamClientHandler opens a stream to the target.
+type StreamClientHandler[I, O transport.Message] func(
+	ctx context.Context,
+	target address.Address,
+) (transport.StreamClient[I, O], error)
+
+// StreamClientInterceptor intercepts the opening of a stream by a client. It must call
+// next to continue the chain, and may wrap the returned transport.StreamClient.
+type StreamClientInterceptor[I, O transport.Message] func(
+	ctx context.Context,
+	target address.Address,
+	next StreamClientHandler[I, O],
+) (transport.StreamClient[I, O], error)
+
+// StreamServerHandler handles a stream opened by a client.
+type StreamServerHandler[I, O transport.Message] func(
+	ctx context.Context,
+	srv transport.StreamServer[I, O],
+) error
+
+// StreamServerInterceptor intercepts a stream handled by a server. It must call next
+// to continue the chain, and may wrap the provided transport.StreamServer.
+type StreamServerInterceptor[I, O transport.Message] func(
+	ctx context.Context,
+	srv transport.StreamServer[I, O],
+	next StreamServerHandler[I, O],
+) error
+
+// Middleware is a set of interceptors. Any of the interceptors may be nil, in which
+// case the Middleware doesn't intercept that kind of call.
+type Middleware[I, O transport.Message] struct {
+	UnaryClient  UnaryClientInterceptor[I, O]
+	UnaryServer  UnaryServerInterceptor[I, O]
+	StreamClient StreamClientInterceptor[I, O]
+	StreamServer StreamServerInterceptor[I, O]
+}
+
+// WrapUnary returns a transport.Unary that applies the provided Middleware to calls
+// to Send and to the handler passed to Handle. The first Middleware is the outermost,
+// meaning it's the first to intercept a call and the last to see its result.
+func WrapUnary[I, O transport.Message](
+	t transport.Unary[I, O],
+	mw ...Middleware[I, O],
+) transport.Unary[I, O] {
+	send := UnaryClientHandler[I, O](t.Send)
+	for i := len(mw) - 1; i >= 0; i-- {
+		if intercept, next := mw[i].UnaryClient, send; intercept != nil {
+			send = func(ctx context.Context, target address.Address, req I) (O, error) {
+				return intercept(ctx, target, req, next)
+			}
+		}
+	}
+	return &unary[I, O]{Unary: t, send: send, mw: mw}
+}
+
+type unary[I, O transport.Message] struct {
+	transport.Unary[I, O]
+	send UnaryClientHandler[I, O]
+	mw   []Middleware[I, O]
+}
+
+// Send implements transport.Unary.
+func (u *unary[I, O]) Send(ctx context.Context, target address.Address, req I) (O, error) {
+	return u.send(ctx, target, req)
+}
+
+// Handle implements transport.Unary.
+func (u *unary[I, O]) Handle(handler func(context.Context, I) (O, error)) {
+	h := UnaryServerHandler[I, O](handler)
+	for i := len(u.mw) - 1; i >= 0; i-- {
+		if intercept, next := u.mw[i].UnaryServer, h; intercept != nil {
+			h = func(ctx context.Context, req I) (O, error) { return intercept(ctx, req, next) }
+		}
+	}
+	u.Unary.Handle(h)
+}
+
+// WrapStream returns a transport.Stream that applies the provided Middleware to calls
+// to Stream and to the handler passed to Handle. The first Middleware is the
+// outermost, meaning it's the first to intercept a call and the last to see its
+// result.
+func WrapStream[I, O transport.Message](
+	t transport.Stream[I, O],
+	mw ...Middleware[I, O],
+) transport.Stream[I, O] {
+	open := StreamClientHandler[I, O](t.Stream)
+	for i := len(mw) - 1; i >= 0; i-- {
+		if intercept, next := mw[i].StreamClient, open; intercept != nil {
+			open = func(ctx context.Context, target address.Address) (transport.StreamClient[I, O], error) {
+				return intercept(ctx, target, next)
+			}
+		}
+	}
+	return &stream[I, O]{wrapped: t, open: open, mw: mw}
+}
+
+type stream[I, O transport.Message] struct {
+	wrapped transport.Stream[I, O]
+	open    StreamClientHandler[I, O]
+	mw      []Middleware[I, O]
+}
+
+// String implements transport.Stream.
+func (s *stream[I, O]) String() string { return s.wrapped.String() }
+
+// Stream implements transport.Stream.
+func (s *stream[I, O]) Stream(
+	ctx context.Context,
+	target address.Address,
+) (transport.StreamClient[I, O], error) {
+	return s.open(ctx, target)
+}
+
+// Handle implements transport.Stream.
+func (s *stream[I, O]) Handle(handler func(context.Context, transport.StreamServer[I, O]) error) {
+	h := StreamServerHandler[I, O](handler)
+	for i := len(s.mw) - 1; i >= 0; i-- {
+		if intercept, next := s.mw[i].StreamServer, h; intercept != nil {
+			h = func(ctx context.Context, srv transport.StreamServer[I, O]) error {
+				return intercept(ctx, srv, next)
+			}
+		}
+	}
+	s.wrapped.Handle(h)
+}
@@ -0,0 +1,206 @@
+package middleware_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/alamos"
+	"github.com/arya-analytics/x/transport"
+	"github.com/arya-analytics/x/transport/middleware"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"time"
+)
+
+type request struct {
+	Value    int
+	Deadline time.Time
+}
+
+// record returns Middleware that appends its name to calls when it intercepts a
+// call, and the name followed by "done" when the call returns.
+func record(name string, calls *[]string) middleware.Middleware[request, int] {
+	return middleware.Middleware[request, int]{
+		UnaryClient: func(
+			ctx context.Context,
+			target address.Address,
+			req request,
+			next middleware.UnaryClientHandler[request, int],
+		) (int, error) {
+			*calls = append(*calls, "client "+name)
+			defer func() { *calls = append(*calls, "client "+name+" done") }()
+			return next(ctx, target, req)
+		},
+		UnaryServer: func(
+			ctx context.Context,
+			req request,
+			next middleware.UnaryServerHandler[request, int],
+		) (int, error) {
+			*calls = append(*calls, "server "+name)
+			return next(ctx, req)
+		},
+		StreamClient: func(
+			ctx context.Context,
+			target address.Address,
+			next middleware.StreamClientHandler[request, int],
+		) (transport.StreamClient[request, int], error) {
+			*calls = append(*calls, "stream client "+name)
+			return next(ctx, target)
+		},
+		StreamServer: func(
+			ctx context.Context,
+			srv transport.StreamServer[request, int],
+			next middleware.StreamServerHandler[request, int],
+		) error {
+			*calls = append(*calls, "stream server "+name)
+			return next(ctx, srv)
+		},
+	}
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		net    *tmock.Network[request, int]
+		client transport.Unary[request, int]
+		server transport.Unary[request, int]
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[request, int]()
+	})
+	wrap := func(mw ...middleware.Middleware[request, int]) {
+		client = middleware.WrapUnary[request, int](net.RouteUnary("localhost:0"), mw...)
+		server = middleware.WrapUnary[request, int](net.RouteUnary("localhost:1"), mw...)
+		server.Handle(func(ctx context.Context, req request) (int, error) {
+			if req.Value < 0 {
+				return 0, errors.New("negative")
+			}
+			return req.Value + 1, nil
+		})
+	}
+	Describe("WrapUnary", func() {
+		It("Should apply interceptors in order", func() {
+			var calls []string
+			wrap(record("a", &calls), record("b", &calls))
+			res, err := client.Send(context.Background(), "localhost:1", request{Value: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+			Expect(calls).To(Equal([]string{
+				"client a",
+				"client b",
+				"server a",
+				"server b",
+				"client b done",
+				"client a done",
+			}))
+		})
+		It("Should allow interceptors to modify requests", func() {
+			wrap(middleware.Middleware[request, int]{
+				UnaryClient: func(
+					ctx context.Context,
+					target address.Address,
+					req request,
+					next middleware.UnaryClientHandler[request, int],
+				) (int, error) {
+					req.Value *= 10
+					return next(ctx, target, req)
+				},
+			})
+			res, err := client.Send(context.Background(), "localhost:1", request{Value: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(11))
+		})
+	})
+	Describe("WrapStream", func() {
+		It("Should apply interceptors in order", func() {
+			var calls []string
+			mw := []middleware.Middleware[request, int]{record("a", &calls), record("b", &calls)}
+			t1 := middleware.WrapStream[request, int](net.RouteStream("localhost:0", 1), mw...)
+			t2 := middleware.WrapStream[request, int](net.RouteStream("localhost:1", 1), mw...)
+			Expect(t1.String()).To(Equal("mock.Stream{} at localhost:0"))
+			t2.Handle(func(ctx context.Context, srv transport.StreamServer[request, int]) error {
+				return nil
+			})
+			stream, err := t1.Stream(context.Background(), "localhost:1")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = stream.Receive()
+			Expect(err).To(MatchError(transport.EOF))
+			Expect(calls).To(Equal([]string{
+				"stream client a",
+				"stream client b",
+				"stream server a",
+				"stream server b",
+			}))
+		})
+	})
+	Describe("Logger", func() {
+		It("Should log successful and failed requests", func() {
+			core, logs := observer.New(zapcore.DebugLevel)
+			wrap(middleware.Logger[request, int](zap.New(core)))
+			_, err := client.Send(context.Background(), "localhost:1", request{Value: 1})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.Send(context.Background(), "localhost:1", request{Value: -1})
+			Expect(err).To(MatchError("negative"))
+			Expect(logs.FilterMessage("unary request sent").Len()).To(Equal(2))
+			Expect(logs.FilterMessage("unary request handled").Len()).To(Equal(2))
+			Expect(logs.FilterLevelExact(zapcore.ErrorLevel).Len()).To(Equal(2))
+		})
+	})
+	Describe("Metrics", func() {
+		It("Should record the latency of requests", func() {
+			mw, metrics := middleware.Metrics[request, int](alamos.New("test"))
+			wrap(mw)
+			for i := 0; i < 3; i++ {
+				_, err := client.Send(context.Background(), "localhost:1", request{Value: 1})
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(metrics.UnaryClient.Count()).To(Equal(3))
+			Expect(metrics.UnaryServer.Count()).To(Equal(3))
+		})
+	})
+	Describe("Deadline", func() {
+		var deadline time.Time
+		BeforeEach(func() {
+			deadline = time.Time{}
+			wrap(
+				middleware.Deadline[request, int](
+					func(req request, d time.Time) request { req.Deadline = d; return req },
+					func(req request) (time.Time, bool) { return req.Deadline, !req.Deadline.IsZero() },
+					time.Second,
+				),
+				// Strip the context so that the deadline can only be propagated
+				// through the request.
+				middleware.Middleware[request, int]{
+					UnaryClient: func(
+						_ context.Context,
+						target address.Address,
+						req request,
+						next middleware.UnaryClientHandler[request, int],
+					) (int, error) {
+						return next(context.Background(), target, req)
+					},
+				},
+			)
+			server.Handle(func(ctx context.Context, req request) (int, error) {
+				deadline, _ = ctx.Deadline()
+				return 0, nil
+			})
+		})
+		It("Should propagate the client's deadline to the server", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			_, err := client.Send(ctx, "localhost:1", request{})
+			Expect(err).ToNot(HaveOccurred())
+			expected, _ := ctx.Deadline()
+			Expect(deadline).To(BeTemporally("==", expected))
+		})
+		It("Should apply the default timeout when no deadline is set", func() {
+			_, err := client.Send(context.Background(), "localhost:1", request{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Second), 100*time.Millisecond))
+		})
+	})
+})
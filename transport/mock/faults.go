@@ -0,0 +1,353 @@
+package mock
+
+import (
+	"container/heap"
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Unreachable is returned when a host attempts to reach a target that it's
+// partitioned from.
+var Unreachable = errors.New("[mock] - target unreachable")
+
+// LinkConfig configures the faults applied to messages sent from one host to another.
+type LinkConfig struct {
+	// Latency is the amount of time it takes for a message to be delivered.
+	Latency time.Duration
+	// Jitter is the maximum amount of time randomly added to Latency. Jitter can cause
+	// messages sent over a stream to be delivered out of order.
+	Jitter time.Duration
+	// DropRate is the probability (between 0 and 1) that a message is discarded.
+	DropRate float64
+	// DuplicateRate is the probability (between 0 and 1) that a message is delivered
+	// twice.
+	DuplicateRate float64
+}
+
+type link struct{ from, to address.Address }
+
+// faults holds the fault configuration of a Network.
+type faults struct {
+	mu          sync.Mutex
+	rand        *rand.Rand
+	defaultLink LinkConfig
+	links       map[link]LinkConfig
+	partitions  map[link]struct{}
+	errors      map[address.Address]error
+}
+
+// Seed seeds the random number generator used to apply faults. Given the same seed
+// and the same sequence of messages, the network applies the same faults. Networks
+// are seeded with 0 by default.
+func (n *Network[I, O]) Seed(seed int64) {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	n.faults.rand = rand.New(rand.NewSource(seed))
+}
+
+// SetDefaultLink sets the faults applied to messages between hosts that don't have
+// a link configured using SetLink.
+func (n *Network[I, O]) SetDefaultLink(cfg LinkConfig) {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	n.faults.defaultLink = cfg
+}
+
+// SetLink sets the faults applied to messages sent from one host to another. Links
+// are directional, so responses from the target are only affected by the link in the
+// opposite direction.
+func (n *Network[I, O]) SetLink(from, to address.Address, cfg LinkConfig) {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	if n.faults.links == nil {
+		n.faults.links = make(map[link]LinkConfig)
+	}
+	n.faults.links[link{from, to}] = cfg
+}
+
+// Partition prevents every host in a from communicating with every host in b (and
+// vice versa). Attempts to open a stream or send a unary request across the
+// partition fail with Unreachable, and messages sent over existing streams are
+// discarded. Partitions accumulate until Heal is called.
+func (n *Network[I, O]) Partition(a, b []address.Address) {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	if n.faults.partitions == nil {
+		n.faults.partitions = make(map[link]struct{})
+	}
+	for _, from := range a {
+		for _, to := range b {
+			n.faults.partitions[link{from, to}] = struct{}{}
+			n.faults.partitions[link{to, from}] = struct{}{}
+		}
+	}
+}
+
+// Heal removes all partitions from the network.
+func (n *Network[I, O]) Heal() {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	n.faults.partitions = nil
+}
+
+// InjectError causes every attempt to open a stream to or send a unary request to
+// the target to fail with the provided error. Passing a nil error removes the
+// injected error.
+func (n *Network[I, O]) InjectError(target address.Address, err error) {
+	n.faults.mu.Lock()
+	defer n.faults.mu.Unlock()
+	if err == nil {
+		delete(n.faults.errors, target)
+		return
+	}
+	if n.faults.errors == nil {
+		n.faults.errors = make(map[address.Address]error)
+	}
+	n.faults.errors[target] = err
+}
+
+// dial returns the error that an attempt by host to reach target should fail with.
+func (f *faults) dial(host, target address.Address) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err, ok := f.errors[target]; ok {
+		return err
+	}
+	if _, ok := f.partitions[link{host, target}]; ok {
+		return errors.Wrapf(Unreachable, "%s -> %s", host, target)
+	}
+	return nil
+}
+
+// schedule returns the delays after which copies of a message sent from one host to
+// another should be delivered. An empty result means the message was discarded.
+func (f *faults) schedule(from, to address.Address) []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.partitions[link{from, to}]; ok {
+		return nil
+	}
+	cfg, ok := f.links[link{from, to}]
+	if !ok {
+		cfg = f.defaultLink
+	}
+	if f.rand == nil {
+		f.rand = rand.New(rand.NewSource(0))
+	}
+	if cfg.DropRate > 0 && f.rand.Float64() < cfg.DropRate {
+		return nil
+	}
+	copies := 1
+	if cfg.DuplicateRate > 0 && f.rand.Float64() < cfg.DuplicateRate {
+		copies = 2
+	}
+	delays := make([]time.Duration, copies)
+	for i := range delays {
+		delays[i] = cfg.Latency
+		if cfg.Jitter > 0 {
+			delays[i] += time.Duration(f.rand.Int63n(int64(cfg.Jitter)))
+		}
+	}
+	return delays
+}
+
+// sleep blocks for the provided duration or until the context is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// wire carries the messages sent in one direction of a stream, applying the
+// network's faults. Messages with no delay are delivered directly, and delayed
+// messages are delivered in order of their arrival time by a background goroutine.
+// Messages that close the stream are never discarded, and are delivered after all
+// in-flight messages.
+type wire[V transport.Message] struct {
+	faults   *faults
+	from, to address.Address
+	ctx      context.Context
+	ch       chan<- message[V]
+	// closed aborts the delivery of messages when the receiving end of the stream
+	// exits. A nil closed channel never aborts delivery.
+	closed <-chan struct{}
+	mu     sync.Mutex
+	queue  deliveryQueue[V]
+	// pending is the number of queued messages that haven't been delivered.
+	pending int
+	// last is the latest arrival time of any in-flight message.
+	last    time.Time
+	seq     int
+	running bool
+	notify  chan struct{}
+}
+
+func newWire[V transport.Message](
+	ctx context.Context,
+	f *faults,
+	from, to address.Address,
+	ch chan<- message[V],
+	closed <-chan struct{},
+) *wire[V] {
+	return &wire[V]{
+		faults: f,
+		ctx:    ctx,
+		from:   from,
+		to:     to,
+		ch:     ch,
+		closed: closed,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// send sends the message over the wire, returning a context error if the wire's
+// context is cancelled, or transport.EOF if the receiving end of the stream has
+// exited.
+func (w *wire[V]) send(msg message[V]) error {
+	for _, d := range w.faults.schedule(w.from, w.to) {
+		if d <= 0 && w.idle() {
+			if err := w.deliver(msg); err != nil {
+				return err
+			}
+			continue
+		}
+		w.enqueue(msg, time.Now().Add(d))
+	}
+	return nil
+}
+
+// close sends a message that closes the stream. Unlike send, close blocks until the
+// message is delivered if there are no messages in flight.
+func (w *wire[V]) close(msg message[V]) {
+	if w.idle() {
+		w.ch <- msg
+		return
+	}
+	w.enqueue(msg, time.Time{})
+}
+
+func (w *wire[V]) idle() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pending == 0
+}
+
+func (w *wire[V]) deliver(msg message[V]) error {
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	case <-w.closed:
+		return transport.EOF
+	case w.ch <- msg:
+		return nil
+	}
+}
+
+// enqueue queues the message for delivery at the provided time. A zero time
+// delivers the message after all in-flight messages.
+func (w *wire[V]) enqueue(msg message[V], at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if at.IsZero() {
+		at = w.last
+	} else if at.After(w.last) {
+		w.last = at
+	}
+	w.seq++
+	w.pending++
+	heap.Push(&w.queue, delivery[V]{msg: msg, at: at, seq: w.seq})
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *wire[V]) run() {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		next := w.queue[0]
+		w.mu.Unlock()
+		if wait := time.Until(next.at); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-w.ctx.Done():
+				t.Stop()
+				w.abandon()
+				return
+			case <-w.notify:
+				t.Stop()
+				continue
+			case <-t.C:
+			}
+		}
+		w.mu.Lock()
+		d := heap.Pop(&w.queue).(delivery[V])
+		w.mu.Unlock()
+		if err := w.deliver(d.msg); err != nil {
+			w.abandon()
+			return
+		}
+		w.mu.Lock()
+		w.pending--
+		w.mu.Unlock()
+	}
+}
+
+// abandon discards all in-flight messages.
+func (w *wire[V]) abandon() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.queue, w.pending, w.running = nil, 0, false
+}
+
+type delivery[V transport.Message] struct {
+	msg message[V]
+	at  time.Time
+	seq int
+}
+
+// deliveryQueue is a heap of deliveries ordered by arrival time and then by the
+// order they were sent.
+type deliveryQueue[V transport.Message] []delivery[V]
+
+func (q deliveryQueue[V]) Len() int { return len(q) }
+
+func (q deliveryQueue[V]) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q deliveryQueue[V]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *deliveryQueue[V]) Push(x any) { *q = append(*q, x.(delivery[V])) }
+
+func (q *deliveryQueue[V]) Pop() any {
+	old := *q
+	d := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return d
+}
@@ -0,0 +1,176 @@
+package mock_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync/atomic"
+	"time"
+)
+
+var _ = Describe("Faults", func() {
+	var (
+		net    *tmock.Network[int, int]
+		client *tmock.Unary[int, int]
+		calls  int32
+	)
+	BeforeEach(func() {
+		calls = 0
+		net = tmock.NewNetwork[int, int]()
+		client = net.RouteUnary("localhost:0")
+		net.RouteUnary("localhost:1").Handle(func(ctx context.Context, in int) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			return in + 1, nil
+		})
+	})
+	Describe("Latency", func() {
+		It("Should delay both the request and the response", func() {
+			net.SetLink("localhost:0", "localhost:1", tmock.LinkConfig{Latency: 10 * time.Millisecond})
+			net.SetLink("localhost:1", "localhost:0", tmock.LinkConfig{Latency: 10 * time.Millisecond})
+			start := time.Now()
+			res, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+			Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+		})
+		It("Should return a context error when the deadline passes", func() {
+			net.SetDefaultLink(tmock.LinkConfig{Latency: time.Second})
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+			defer cancel()
+			_, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+	Describe("Drop", func() {
+		It("Should block until the context is cancelled", func() {
+			net.SetDefaultLink(tmock.LinkConfig{DropRate: 1})
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+			defer cancel()
+			_, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(atomic.LoadInt32(&calls)).To(BeZero())
+		})
+		It("Should drop the same messages given the same seed", func() {
+			run := func() (dropped []bool) {
+				net.Seed(42)
+				net.SetDefaultLink(tmock.LinkConfig{DropRate: 0.5})
+				for i := 0; i < 20; i++ {
+					ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+					_, err := client.Send(ctx, "localhost:1", i)
+					cancel()
+					dropped = append(dropped, err != nil)
+				}
+				return dropped
+			}
+			first := run()
+			Expect(first).To(ContainElement(true))
+			Expect(first).To(ContainElement(false))
+			Expect(run()).To(Equal(first))
+		})
+	})
+	Describe("Duplicate", func() {
+		It("Should call the handler twice", func() {
+			net.SetLink("localhost:0", "localhost:1", tmock.LinkConfig{DuplicateRate: 1})
+			res, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+			Eventually(func() int32 { return atomic.LoadInt32(&calls) }).Should(Equal(int32(2)))
+		})
+	})
+	Describe("Partition", func() {
+		It("Should return an Unreachable error until the partition is healed", func() {
+			net.Partition([]address.Address{"localhost:0"}, []address.Address{"localhost:1"})
+			_, err := client.Send(ctx, "localhost:1", 1)
+			Expect(errors.Is(err, tmock.Unreachable)).To(BeTrue())
+			net.Heal()
+			res, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+		It("Should discard messages sent over an open stream", func() {
+			snet := tmock.NewNetwork[int, int]()
+			t1 := snet.RouteStream("localhost:0", 10)
+			received := make(chan int, 10)
+			snet.RouteStream("localhost:1", 10).Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+				for {
+					msg, err := srv.Receive()
+					if err != nil {
+						return nil
+					}
+					received <- msg
+				}
+			})
+			stream, err := t1.Stream(ctx, "localhost:1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stream.Send(1)).To(Succeed())
+			Eventually(received).Should(Receive(Equal(1)))
+			snet.Partition([]address.Address{"localhost:0"}, []address.Address{"localhost:1"})
+			Expect(stream.Send(2)).To(Succeed())
+			Consistently(received, 5*time.Millisecond).ShouldNot(Receive())
+			snet.Heal()
+			Expect(stream.Send(3)).To(Succeed())
+			Eventually(received).Should(Receive(Equal(3)))
+			Expect(stream.CloseSend()).To(Succeed())
+		})
+	})
+	Describe("InjectError", func() {
+		It("Should fail requests with the injected error", func() {
+			injected := errors.New("injected")
+			net.InjectError("localhost:1", injected)
+			_, err := client.Send(ctx, "localhost:1", 1)
+			Expect(err).To(MatchError(injected))
+			net.InjectError("localhost:1", nil)
+			_, err = client.Send(ctx, "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("Should fail opening a stream with the injected error", func() {
+			snet := tmock.NewNetwork[int, int]()
+			t1 := snet.RouteStream("localhost:0", 10)
+			snet.RouteStream("localhost:1", 10).Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+				return nil
+			})
+			injected := errors.New("injected")
+			snet.InjectError("localhost:1", injected)
+			_, err := t1.Stream(ctx, "localhost:1")
+			Expect(err).To(MatchError(injected))
+		})
+	})
+	Describe("Stream Latency", func() {
+		It("Should deliver delayed messages in order followed by the close", func() {
+			snet := tmock.NewNetwork[int, int]()
+			snet.SetDefaultLink(tmock.LinkConfig{Latency: 2 * time.Millisecond})
+			t1 := snet.RouteStream("localhost:0", 10)
+			snet.RouteStream("localhost:1", 10).Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+				for {
+					msg, err := srv.Receive()
+					if errors.Is(err, transport.EOF) {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					if err := srv.Send(msg * 2); err != nil {
+						return err
+					}
+				}
+			})
+			stream, err := t1.Stream(ctx, "localhost:1")
+			Expect(err).ToNot(HaveOccurred())
+			for i := 1; i <= 5; i++ {
+				Expect(stream.Send(i)).To(Succeed())
+			}
+			Expect(stream.CloseSend()).To(Succeed())
+			for i := 1; i <= 5; i++ {
+				res, err := stream.Receive()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(i * 2))
+			}
+			_, err = stream.Receive()
+			Expect(err).To(MatchError(transport.EOF))
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package mock
+
+import (
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"time"
+)
+
+// Direction is the direction a message was sent over a stream.
+type Direction uint8
+
+const (
+	// DirectionRequest is a message sent from the client to the server.
+	DirectionRequest Direction = iota + 1
+	// DirectionResponse is a message sent from the server to the client.
+	DirectionResponse
+)
+
+// StreamMessage is a message sent over a stream.
+type StreamMessage[I, O transport.Message] struct {
+	Direction Direction
+	// Request is set if Direction is DirectionRequest.
+	Request I
+	// Response is set if Direction is DirectionResponse.
+	Response O
+	// Time is the time the message was sent.
+	Time time.Time
+}
+
+// StreamEntry is a single stream session in the network's history.
+type StreamEntry[I, O transport.Message] struct {
+	// Host is the address of the client that opened the stream.
+	Host address.Address
+	// Target is the address of the server the stream was opened to.
+	Target address.Address
+	// Opened is the time the stream was opened.
+	Opened time.Time
+	// Messages are the messages sent over the stream in the order they were sent.
+	// A message is recorded when it's passed to Send on a stream that hasn't
+	// failed, so messages discarded by the network or aborted by the stream closing
+	// mid-send are included.
+	Messages []StreamMessage[I, O]
+	// Closed is the time the server closed the stream. Closed is zero if the stream
+	// is still open.
+	Closed time.Time
+	// Error is the error the server closed the stream with. Error is transport.EOF
+	// if the server closed the stream successfully.
+	Error error
+}
+
+// Requests returns the requests sent over the stream in the order they were sent.
+func (s StreamEntry[I, O]) Requests() []I {
+	var reqs []I
+	for _, msg := range s.Messages {
+		if msg.Direction == DirectionRequest {
+			reqs = append(reqs, msg.Request)
+		}
+	}
+	return reqs
+}
+
+// Responses returns the responses sent over the stream in the order they were sent.
+func (s StreamEntry[I, O]) Responses() []O {
+	var res []O
+	for _, msg := range s.Messages {
+		if msg.Direction == DirectionResponse {
+			res = append(res, msg.Response)
+		}
+	}
+	return res
+}
+
+// StreamHistory returns a snapshot of every stream opened on the network in the
+// order they were opened.
+func (n *Network[I, O]) StreamHistory() []StreamEntry[I, O] {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	entries := make([]StreamEntry[I, O], len(n.streamEntries))
+	for i, e := range n.streamEntries {
+		entries[i] = *e
+		entries[i].Messages = append([]StreamMessage[I, O](nil), e.Messages...)
+	}
+	return entries
+}
+
+// StreamsBetween returns a snapshot of every stream opened from host to target in the
+// order they were opened.
+func (n *Network[I, O]) StreamsBetween(host, target address.Address) []StreamEntry[I, O] {
+	var entries []StreamEntry[I, O]
+	for _, e := range n.StreamHistory() {
+		if e.Host == host && e.Target == target {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// MessagesBetween returns every message sent over streams opened from host to target,
+// ordered by the time they were sent.
+func (n *Network[I, O]) MessagesBetween(host, target address.Address) []StreamMessage[I, O] {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var msgs []StreamMessage[I, O]
+	for _, msg := range n.streamMessages {
+		if msg.entry.Host == host && msg.entry.Target == target {
+			msgs = append(msgs, msg.StreamMessage)
+		}
+	}
+	return msgs
+}
+
+// recordedMessage is a StreamMessage in the network's global send order.
+type recordedMessage[I, O transport.Message] struct {
+	StreamMessage[I, O]
+	entry *StreamEntry[I, O]
+}
+
+func (n *Network[I, O]) openStreamEntry(host, target address.Address) *StreamEntry[I, O] {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	e := &StreamEntry[I, O]{Host: host, Target: target, Opened: time.Now()}
+	n.streamEntries = append(n.streamEntries, e)
+	return e
+}
+
+func (n *Network[I, O]) recordRequest(e *StreamEntry[I, O], req I) {
+	n.recordMessage(e, StreamMessage[I, O]{Direction: DirectionRequest, Request: req})
+}
+
+func (n *Network[I, O]) recordResponse(e *StreamEntry[I, O], res O) {
+	n.recordMessage(e, StreamMessage[I, O]{Direction: DirectionResponse, Response: res})
+}
+
+func (n *Network[I, O]) recordMessage(e *StreamEntry[I, O], msg StreamMessage[I, O]) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	msg.Time = time.Now()
+	e.Messages = append(e.Messages, msg)
+	n.streamMessages = append(n.streamMessages, recordedMessage[I, O]{StreamMessage: msg, entry: e})
+}
+
+func (n *Network[I, O]) closeStreamEntry(e *StreamEntry[I, O], err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	e.Closed, e.Error = time.Now(), err
+}
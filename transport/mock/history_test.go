@@ -0,0 +1,90 @@
+package mock_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/transport"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	var (
+		net *tmock.Network[int, int]
+		t1  *tmock.Stream[int, int]
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[int, int]()
+		t1 = net.RouteStream("localhost:0", 10)
+		net.RouteStream("localhost:1", 10).Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			for {
+				msg, err := srv.Receive()
+				if errors.Is(err, transport.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if msg < 0 {
+					return errors.New("negative")
+				}
+				if err := srv.Send(msg * 2); err != nil {
+					return err
+				}
+			}
+		})
+	})
+	exchange := func(values ...int) transport.StreamClient[int, int] {
+		stream, err := t1.Stream(ctx, "localhost:1")
+		Expect(err).ToNot(HaveOccurred())
+		for _, v := range values {
+			Expect(stream.Send(v)).To(Succeed())
+			if v >= 0 {
+				res, err := stream.Receive()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(v * 2))
+			}
+		}
+		return stream
+	}
+	It("Should record the messages sent in each direction", func() {
+		stream := exchange(1, 2)
+		Expect(stream.CloseSend()).To(Succeed())
+		_, err := stream.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+		entries := net.StreamsBetween("localhost:0", "localhost:1")
+		Expect(entries).To(HaveLen(1))
+		e := entries[0]
+		Expect(e.Requests()).To(Equal([]int{1, 2}))
+		Expect(e.Responses()).To(Equal([]int{2, 4}))
+		Expect(e.Messages).To(HaveLen(4))
+		Expect(e.Messages[0].Direction).To(Equal(tmock.DirectionRequest))
+		Expect(e.Messages[1].Direction).To(Equal(tmock.DirectionResponse))
+		Expect(e.Messages[1].Time).ToNot(BeTemporally("<", e.Messages[0].Time))
+		Expect(e.Closed.IsZero()).To(BeFalse())
+		Expect(e.Error).To(MatchError(transport.EOF))
+	})
+	It("Should record the error the stream was closed with", func() {
+		stream := exchange(1, -1)
+		_, err := stream.Receive()
+		Expect(err).To(MatchError("negative"))
+		e := net.StreamHistory()[0]
+		Expect(e.Error).To(MatchError("negative"))
+		Expect(e.Requests()).To(Equal([]int{1, -1}))
+	})
+	It("Should return the messages between two hosts across streams in order", func() {
+		s1 := exchange(1)
+		s2 := exchange(2)
+		Expect(s1.CloseSend()).To(Succeed())
+		Expect(s2.CloseSend()).To(Succeed())
+		msgs := net.MessagesBetween("localhost:0", "localhost:1")
+		Expect(msgs).To(HaveLen(4))
+		Expect(msgs[0].Request).To(Equal(1))
+		Expect(msgs[1].Response).To(Equal(2))
+		Expect(msgs[2].Request).To(Equal(2))
+		Expect(msgs[3].Response).To(Equal(4))
+		Expect(net.StreamHistory()).To(HaveLen(2))
+		Expect(net.StreamsBetween("localhost:1", "localhost:0")).To(BeEmpty())
+	})
+})
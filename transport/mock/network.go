@@ -9,13 +9,19 @@ import (
 
 // Network is a mock network implementation that is ideal for in-memory testing
 // scenarios. It serves as a factory for transport.Stream and transport.Unary.
+//
+// By default, the network delivers every message instantly and reliably. To simulate
+// an unreliable network, see SetLink, SetDefaultLink, Partition, and InjectError.
 type Network[I, O transport.Message] struct {
 	mu sync.Mutex
-	// Entries is a slice of entries in the network. Entries currently only supports
-	// unary entries.
-	Entries      []NetworkEntry[I, O]
-	UnaryRoutes  map[address.Address]*Unary[I, O]
-	StreamRoutes map[address.Address]*Stream[I, O]
+	// Entries is a slice of unary entries in the network. To inspect the streams
+	// opened on the network, see StreamHistory.
+	Entries        []NetworkEntry[I, O]
+	UnaryRoutes    map[address.Address]*Unary[I, O]
+	StreamRoutes   map[address.Address]*Stream[I, O]
+	faults         faults
+	streamEntries  []*StreamEntry[I, O]
+	streamMessages []recordedMessage[I, O]
 }
 
 // NetworkEntry is a single entry in the network's history. NetworkEntry
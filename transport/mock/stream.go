@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/arya-analytics/x/address"
 	"github.com/arya-analytics/x/transport"
+	"sync"
 )
 
 type message[V transport.Message] struct {
@@ -18,6 +19,9 @@ type Stream[I, O transport.Message] struct {
 	BufferSize int
 	Network    *Network[I, O]
 	Handler    func(ctx context.Context, srv transport.StreamServer[I, O]) error
+	// handlerMu guards Handler, so a handler can be registered while other nodes
+	// are opening streams to it.
+	handlerMu sync.RWMutex
 }
 
 // Stream implements the transport.Stream interface.
@@ -26,25 +30,48 @@ func (s *Stream[I, O]) Stream(
 	target address.Address,
 ) (transport.StreamClient[I, O], error) {
 	route, ok := s.Network.StreamRoutes[target]
-	if !ok || route.Handler == nil {
+	if !ok {
 		return nil, address.TargetNotFound(target)
 	}
-	req, res := make(chan message[I], s.BufferSize), make(chan message[O], route.BufferSize)
-	server := &serverStream[I, O]{ctx: ctx, requests: req, responses: res}
-	serverClosed := make(chan struct{})
+	route.handlerMu.RLock()
+	handler := route.Handler
+	route.handlerMu.RUnlock()
+	if handler == nil {
+		return nil, address.TargetNotFound(target)
+	}
+	if err := s.Network.faults.dial(s.Address, target); err != nil {
+		return nil, err
+	}
+	var (
+		req          = make(chan message[I], s.BufferSize)
+		res          = make(chan message[O], route.BufferSize)
+		serverClosed = make(chan struct{})
+		reqWire      = newWire[I](ctx, &s.Network.faults, s.Address, target, req, serverClosed)
+		resWire      = newWire[O](ctx, &s.Network.faults, target, s.Address, res, nil)
+		entry        = s.Network.openStreamEntry(s.Address, target)
+		server       = &serverStream[I, O]{
+			ctx:       ctx,
+			network:   s.Network,
+			entry:     entry,
+			requests:  req,
+			responses: resWire,
+		}
+	)
 	go func() {
-		err := route.Handler(ctx, server)
+		err := handler(ctx, server)
 		if err == nil {
 			err = transport.EOF
 		}
-		res <- message[O]{error: err}
+		s.Network.closeStreamEntry(entry, err)
+		resWire.close(message[O]{error: err})
 		close(serverClosed)
 	}()
 	return &clientStream[I, O]{
-		ctx:          ctx,
-		requests:     req,
-		responses:    res,
-		serverClosed: serverClosed,
+		ctx:       ctx,
+		network:   s.Network,
+		entry:     entry,
+		requests:  reqWire,
+		responses: res,
 	}, nil
 }
 
@@ -56,6 +83,8 @@ func (s *Stream[I, O]) String() string {
 func (s *Stream[I, O]) Handle(handler func(
 	ctx context.Context,
 	srv transport.StreamServer[I, O]) error) {
+	s.handlerMu.Lock()
+	defer s.handlerMu.Unlock()
 	s.Handler = handler
 }
 
@@ -64,8 +93,10 @@ type serverStream[I, O transport.Message] struct {
 	// practice, but in this case we're essentially using it as a data container,
 	// and we have a very good grasp on how it's used.
 	ctx       context.Context
+	network   *Network[I, O]
+	entry     *StreamEntry[I, O]
 	requests  <-chan message[I]
-	responses chan<- message[O]
+	responses *wire[O]
 	// inboundFatalErr indicates the request direction of the serverStream
 	// closed. This should be a transport.EOF error if the serverStream closed successfully,
 	// a context error if ctx was canceled, and any other error if the serverStream died
@@ -86,13 +117,12 @@ func (s *serverStream[I, O]) Send(res O) error {
 		s.outboundFatalErr = s.ctx.Err()
 		return s.outboundFatalErr
 	}
-	select {
-	case <-s.ctx.Done():
-		s.outboundFatalErr = s.ctx.Err()
+	s.network.recordResponse(s.entry, res)
+	if err := s.responses.send(message[O]{value: res}); err != nil {
+		s.outboundFatalErr = err
 		return s.outboundFatalErr
-	case s.responses <- message[O]{value: res}:
-		return nil
 	}
+	return nil
 }
 
 // Receive implements the transport.StreamClient interface.
@@ -120,19 +150,28 @@ type clientStream[I, O transport.Message] struct {
 	// ctx is the context the serverStream was started with. Yes, Yes! I know this is a bad
 	// practice, but in this case we're essentially using it as a data container,
 	// and we have a very good grasp on how it's used.
-	ctx          context.Context
-	requests     chan<- message[I]
-	responses    <-chan message[O]
-	serverClosed chan struct{}
+	ctx       context.Context
+	network   *Network[I, O]
+	entry     *StreamEntry[I, O]
+	requests  *wire[I]
+	responses <-chan message[O]
 	// inboundFatalErr indicates the request direction of the serverStream
 	// closed. This should be a transport.EOF error if the serverStream closed successfully,
 	// a context error if ctx was canceled, and any other error if the serverStream died
 	// at the hands of the caller.
-	inboundFatalErr  error
+	inboundFatalErr error
+	// outboundMu guards outboundFatalErr, which is also set by Receive when the server
+	// closes the stream, so that Receive can be called concurrently with Send and
+	// CloseSend.
+	outboundMu       sync.Mutex
 	outboundFatalErr error
 }
 
 func (c *clientStream[I, O]) Send(req I) error {
+	// The lock is held while sending so that a concurrent CloseSend can't deliver
+	// its close message ahead of a message that's already being sent.
+	c.outboundMu.Lock()
+	defer c.outboundMu.Unlock()
 	if c.outboundFatalErr != nil {
 		return c.outboundFatalErr
 	}
@@ -140,16 +179,12 @@ func (c *clientStream[I, O]) Send(req I) error {
 		c.outboundFatalErr = c.ctx.Err()
 		return c.outboundFatalErr
 	}
-	select {
-	case <-c.serverClosed:
-		c.outboundFatalErr = transport.EOF
-		return c.outboundFatalErr
-	case <-c.ctx.Done():
-		c.outboundFatalErr = c.ctx.Err()
+	c.network.recordRequest(c.entry, req)
+	if err := c.requests.send(message[I]{value: req}); err != nil {
+		c.outboundFatalErr = err
 		return c.outboundFatalErr
-	case c.requests <- message[I]{value: req}:
-		return nil
 	}
+	return nil
 }
 
 func (c *clientStream[I, O]) Receive() (res O, err error) {
@@ -173,10 +208,13 @@ func (c *clientStream[I, O]) Receive() (res O, err error) {
 
 // CloseSend implements the transport.StreamCloser interface.
 func (c *clientStream[I, O]) CloseSend() error {
+	c.outboundMu.Lock()
 	if c.outboundFatalErr != nil {
+		c.outboundMu.Unlock()
 		return nil
 	}
 	c.outboundFatalErr = transport.EOF
-	c.requests <- message[I]{error: c.outboundFatalErr}
+	c.outboundMu.Unlock()
+	c.requests.close(message[I]{error: c.outboundFatalErr})
 	return nil
 }
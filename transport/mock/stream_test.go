@@ -75,4 +75,32 @@ var _ = Describe("Stream", func() {
 		Expect(client.Send(1)).To(MatchError(transport.EOF))
 		Expect(client.CloseSend()).To(Succeed())
 	})
+	It("Should allow Send to be called concurrently with Receive and CloseSend", func() {
+		t1 := net.RouteStream("localhost:0", 0)
+		t2 := net.RouteStream("localhost:1", 0)
+		t2.Handle(func(ctx context.Context, srv transport.StreamServer[int, int]) error {
+			for i := 0; i < 10; i++ {
+				if _, err := srv.Receive(); err != nil {
+					return err
+				}
+			}
+			// Close the stream while the client is still sending.
+			return nil
+		})
+		client, err := t1.Stream(ctx, "localhost:1")
+		Expect(err).ToNot(HaveOccurred())
+		sent := make(chan error, 1)
+		go func() {
+			for i := 0; ; i++ {
+				if err := client.Send(i); err != nil {
+					sent <- err
+					return
+				}
+			}
+		}()
+		_, err = client.Receive()
+		Expect(err).To(MatchError(transport.EOF))
+		Expect(client.CloseSend()).To(Succeed())
+		Eventually(sent).Should(Receive(MatchError(transport.EOF)))
+	})
 })
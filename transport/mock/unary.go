@@ -14,14 +14,46 @@ type Unary[I, O transport.Message] struct {
 	Handler func(context.Context, I) (O, error)
 }
 
-// Send implements the transport.Unary interface.
+// Send implements the transport.Unary interface. If the request or response is
+// discarded by the network, Send blocks until the context is cancelled. If the
+// request is duplicated, the handler is called twice, and only the first response is
+// returned.
 func (t *Unary[I, O]) Send(ctx context.Context, target address.Address, req I) (res O, err error) {
 	route, ok := t.Network.UnaryRoutes[target]
 	if !ok || route.Handler == nil {
 		return res, address.TargetNotFound(target)
 	}
+	if err = t.Network.faults.dial(t.Address, target); err != nil {
+		return res, err
+	}
+	reqDelays := t.Network.faults.schedule(t.Address, target)
+	if len(reqDelays) == 0 {
+		<-ctx.Done()
+		return res, ctx.Err()
+	}
+	for _, d := range reqDelays[1:] {
+		d := d
+		go func() {
+			if sleep(ctx, d) == nil {
+				_, _ = route.Handler(ctx, req)
+			}
+		}()
+	}
+	if err = sleep(ctx, reqDelays[0]); err != nil {
+		return res, err
+	}
 	res, err = route.Handler(ctx, req)
 	t.Network.appendEntry(t.Address, target, req, res, err)
+	resDelays := t.Network.faults.schedule(target, t.Address)
+	if len(resDelays) == 0 {
+		<-ctx.Done()
+		var zero O
+		return zero, ctx.Err()
+	}
+	if sErr := sleep(ctx, resDelays[0]); sErr != nil {
+		var zero O
+		return zero, sErr
+	}
 	return res, err
 }
 
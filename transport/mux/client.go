@@ -0,0 +1,152 @@
+package mux
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"sync"
+	"time"
+)
+
+// conn is a stream opened by a Unary client, shared by all requests to its target.
+type conn[I, O transport.Message] struct {
+	stream transport.StreamClient[Request[I], Response[O]]
+	cancel context.CancelFunc
+	// sendMu serializes sends on the stream.
+	sendMu  sync.Mutex
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan Response[O]
+	// done is closed once the stream stops receiving, after which err holds the error
+	// it stopped with.
+	done chan struct{}
+	err  error
+}
+
+// openConn opens a stream to the target bound to ctx. cancel cancels ctx, and is
+// called when the conn is closed.
+func openConn[I, O transport.Message](
+	ctx context.Context,
+	cancel context.CancelFunc,
+	t transport.Stream[Request[I], Response[O]],
+	target address.Address,
+) (*conn[I, O], error) {
+	stream, err := t.Stream(ctx, target)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c := &conn[I, O]{
+		stream:  stream,
+		cancel:  cancel,
+		pending: make(map[uint64]chan Response[O]),
+		done:    make(chan struct{}),
+	}
+	go c.receive()
+	return c, nil
+}
+
+func (c *conn[I, O]) receive() {
+	defer close(c.done)
+	for {
+		res, err := c.stream.Receive()
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[res.ID]
+		delete(c.pending, res.ID)
+		c.mu.Unlock()
+		// Responses to cancelled requests are discarded.
+		if ok {
+			ch <- res
+		}
+	}
+}
+
+func (c *conn[I, O]) send(ctx context.Context, req I) (res O, err error) {
+	id, ch := c.register()
+	r := Request[I]{ID: id, Payload: req}
+	if deadline, ok := ctx.Deadline(); ok {
+		if r.Timeout = time.Until(deadline); r.Timeout <= 0 {
+			c.unregister(id)
+			return res, context.DeadlineExceeded
+		}
+	}
+	if err := c.write(r); err != nil {
+		c.unregister(id)
+		return res, err
+	}
+	select {
+	case <-ctx.Done():
+		c.unregister(id)
+		// If the stream has broken, there's nothing to cancel.
+		_ = c.write(Request[I]{ID: id, Cancel: true})
+		return res, ctx.Err()
+	case r := <-ch:
+		return result(ctx, r)
+	case <-c.done:
+		// The response may have arrived right before the stream stopped.
+		select {
+		case r := <-ch:
+			return result(ctx, r)
+		default:
+			return res, c.err
+		}
+	}
+}
+
+// result returns the payload and error of the response.
+func result[O transport.Message](ctx context.Context, r Response[O]) (res O, err error) {
+	if r.Error == "" {
+		return r.Payload, nil
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	// The server's deadline is never earlier than the request's, but its timer may
+	// fire before the timer of the request's context.
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return res, context.DeadlineExceeded
+	}
+	return res, errors.New(r.Error)
+}
+
+func (c *conn[I, O]) register() (uint64, chan Response[O]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	ch := make(chan Response[O], 1)
+	c.pending[c.nextID] = ch
+	return c.nextID, ch
+}
+
+func (c *conn[I, O]) unregister(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+func (c *conn[I, O]) write(r Request[I]) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.stream.Send(r)
+}
+
+func (c *conn[I, O]) broken() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *conn[I, O]) close() {
+	c.sendMu.Lock()
+	_ = c.stream.CloseSend()
+	c.sendMu.Unlock()
+	c.cancel()
+}
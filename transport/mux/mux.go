@@ -0,0 +1,194 @@
+// Package mux multiplexes unary requests over long-lived streams, avoiding the cost
+// of setting up a connection for every request.
+//
+// Unary implements transport.Unary on top of a transport.Stream. The client keeps a
+// single stream open to each target, tags every request with an ID, and matches the
+// server's responses to waiting requests by ID. Requests are handled concurrently by
+// the server, so responses may arrive in any order. Cancelling a request's context
+// cancels the context of the server's handler.
+package mux
+
+import (
+	"context"
+	"fmt"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"sync"
+	"time"
+)
+
+// Request is a unary request sent over a stream.
+type Request[I transport.Message] struct {
+	// ID identifies the request within the stream.
+	ID uint64
+	// Payload is the request sent by the caller.
+	Payload I
+	// Timeout is the time the server has to handle the request. A Timeout of zero
+	// means no timeout.
+	Timeout time.Duration
+	// Cancel is true if the request cancels the request with the same ID.
+	Cancel bool
+}
+
+// Response is the response to a unary request sent over a stream.
+type Response[O transport.Message] struct {
+	// ID is the ID of the request the response is for.
+	ID uint64
+	// Payload is the response returned by the server's handler.
+	Payload O
+	// Error is the message of the error returned by the server's handler, if any.
+	Error string
+}
+
+// Unary is an implementation of transport.Unary that multiplexes requests over
+// streams opened using Transport. The zero value is ready to use once Transport is
+// set.
+type Unary[I, O transport.Message] struct {
+	// Transport is the stream transport requests are multiplexed over.
+	Transport transport.Stream[Request[I], Response[O]]
+	// Timeout is the timeout applied to requests whose context has no deadline. A
+	// Timeout of zero means no timeout.
+	Timeout time.Duration
+	mu      sync.Mutex
+	conns   map[address.Address]*conn[I, O]
+	// dials holds the streams being opened, so concurrent requests to a target share
+	// a single dial.
+	dials map[address.Address]*dial[I, O]
+}
+
+var _ transport.Unary[any, any] = (*Unary[any, any])(nil)
+
+// Send implements the transport.Unary interface. Send opens a stream to the target if
+// one isn't already open. If the stream breaks while the request is in flight, Send
+// returns the error the stream broke with, and the next request opens a new stream.
+// Opening a stream is bound by the request's context, and doesn't block requests to
+// other targets.
+func (u *Unary[I, O]) Send(ctx context.Context, target address.Address, req I) (res O, err error) {
+	if _, ok := ctx.Deadline(); !ok && u.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.Timeout)
+		defer cancel()
+	}
+	c, err := u.conn(ctx, target)
+	if err != nil {
+		return res, err
+	}
+	return c.send(ctx, req)
+}
+
+// Handle implements the transport.Unary interface. Handle registers a handler for the
+// streams opened by Unary clients on Transport.
+func (u *Unary[I, O]) Handle(handler func(context.Context, I) (O, error)) {
+	u.Transport.Handle(func(
+		ctx context.Context,
+		srv transport.StreamServer[Request[I], Response[O]],
+	) error {
+		return serve(ctx, srv, handler)
+	})
+}
+
+// String implements the transport.Unary interface.
+func (u *Unary[I, O]) String() string {
+	return fmt.Sprintf("mux.Unary{} over %s", u.Transport)
+}
+
+// Close closes every stream opened by Send. Requests in flight fail with the error
+// the stream closes with.
+func (u *Unary[I, O]) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for target, c := range u.conns {
+		c.close()
+		delete(u.conns, target)
+	}
+	for target, d := range u.dials {
+		d.abandon()
+		delete(u.dials, target)
+	}
+	return nil
+}
+
+// conn returns the open stream to the target, opening a new one if necessary. The
+// stream is opened outside of the lock, and requests waiting on the same target
+// share the dial.
+func (u *Unary[I, O]) conn(ctx context.Context, target address.Address) (*conn[I, O], error) {
+	u.mu.Lock()
+	if c, ok := u.conns[target]; ok && !c.broken() {
+		u.mu.Unlock()
+		return c, nil
+	}
+	d, ok := u.dials[target]
+	if !ok {
+		d = u.dial(target)
+	}
+	d.waiters++
+	u.mu.Unlock()
+	select {
+	case <-d.done:
+		return d.c, d.err
+	case <-ctx.Done():
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		// The dial is abandoned once no request is waiting on it.
+		if d.waiters--; d.waiters == 0 && u.dials[target] == d {
+			d.abandon()
+			delete(u.dials, target)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// dial starts opening a stream to the target. u.mu must be held.
+func (u *Unary[I, O]) dial(target address.Address) *dial[I, O] {
+	// The stream outlives the request that opened it, so it's bound to its own
+	// context.
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &dial[I, O]{cancel: cancel, done: make(chan struct{})}
+	if u.dials == nil {
+		u.dials = make(map[address.Address]*dial[I, O])
+	}
+	u.dials[target] = d
+	go func() {
+		c, err := openConn[I, O](ctx, cancel, u.Transport, target)
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if d.abandoned {
+			// Requests still waiting on an abandoned dial were waiting when the
+			// client closed.
+			if err == nil {
+				c.close()
+				c, err = nil, context.Canceled
+			}
+		} else {
+			delete(u.dials, target)
+			if err == nil {
+				if u.conns == nil {
+					u.conns = make(map[address.Address]*conn[I, O])
+				}
+				u.conns[target] = c
+			}
+		}
+		d.c, d.err = c, err
+		close(d.done)
+	}()
+	return d
+}
+
+// dial is a stream being opened to a target.
+type dial[I, O transport.Message] struct {
+	cancel context.CancelFunc
+	// waiters is the number of requests waiting on the dial.
+	waiters   int
+	abandoned bool
+	// done is closed once the dial completes, after which c and err hold its
+	// result.
+	done chan struct{}
+	c    *conn[I, O]
+	err  error
+}
+
+// abandon cancels the dial. u.mu must be held.
+func (d *dial[I, O]) abandon() {
+	d.abandoned = true
+	d.cancel()
+}
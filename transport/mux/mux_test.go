@@ -0,0 +1,153 @@
+package mux_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/arya-analytics/x/transport/mux"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+type streamTransport = transport.Stream[mux.Request[int], mux.Response[int]]
+
+// blockingDialer blocks streams opened to target until their context is cancelled.
+type blockingDialer struct {
+	streamTransport
+	target address.Address
+}
+
+func (b blockingDialer) Stream(
+	ctx context.Context,
+	target address.Address,
+) (transport.StreamClient[mux.Request[int], mux.Response[int]], error) {
+	if target == b.target {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return b.streamTransport.Stream(ctx, target)
+}
+
+var _ = Describe("Unary", func() {
+	var (
+		net    *tmock.Network[mux.Request[int], mux.Response[int]]
+		client *mux.Unary[int, int]
+		server *mux.Unary[int, int]
+		target *tmock.Stream[mux.Request[int], mux.Response[int]]
+	)
+	BeforeEach(func() {
+		net = tmock.NewNetwork[mux.Request[int], mux.Response[int]]()
+		client = &mux.Unary[int, int]{Transport: net.RouteStream("", 10)}
+		target = net.RouteStream("", 10)
+		server = &mux.Unary[int, int]{Transport: target}
+	})
+	AfterEach(func() { Expect(client.Close()).To(Succeed()) })
+	It("Should send concurrent requests over a single stream", func() {
+		server.Handle(func(ctx context.Context, req int) (int, error) {
+			// Later requests respond first, so responses arrive out of order.
+			time.Sleep(time.Duration(10-req) * time.Millisecond)
+			return req * 2, nil
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				res, err := client.Send(ctx, target.Address, i)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(i * 2))
+			}()
+		}
+		wg.Wait()
+		Expect(net.StreamHistory()).To(HaveLen(1))
+	})
+	It("Should return the error returned by the handler", func() {
+		server.Handle(func(ctx context.Context, req int) (int, error) {
+			return 0, errors.New("handler failed")
+		})
+		_, err := client.Send(ctx, target.Address, 1)
+		Expect(err).To(MatchError("handler failed"))
+	})
+	It("Should cancel the handler when the request is cancelled", func() {
+		cancelled := make(chan error, 1)
+		server.Handle(func(ctx context.Context, req int) (int, error) {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return 0, ctx.Err()
+		})
+		cCtx, cancel := context.WithCancel(ctx)
+		errs := make(chan error)
+		go func() {
+			_, err := client.Send(cCtx, target.Address, 1)
+			errs <- err
+		}()
+		Consistently(errs, 5*time.Millisecond).ShouldNot(Receive())
+		cancel()
+		Eventually(errs).Should(Receive(MatchError(context.Canceled)))
+		Eventually(cancelled).Should(Receive(MatchError(context.Canceled)))
+	})
+	It("Should propagate the request timeout to the handler", func() {
+		client.Timeout = 10 * time.Millisecond
+		deadlines := make(chan bool, 1)
+		server.Handle(func(ctx context.Context, req int) (int, error) {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		_, err := client.Send(ctx, target.Address, 1)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(<-deadlines).To(BeTrue())
+	})
+	It("Should open a new stream after the stream breaks", func() {
+		var (
+			mu    sync.Mutex
+			first = true
+		)
+		handler := func(ctx context.Context, req int) (int, error) { return req, nil }
+		server.Handle(handler)
+		served := target.Handler
+		target.Handle(func(
+			ctx context.Context,
+			srv transport.StreamServer[mux.Request[int], mux.Response[int]],
+		) error {
+			mu.Lock()
+			breakStream := first
+			first = false
+			mu.Unlock()
+			if breakStream {
+				_, err := srv.Receive()
+				Expect(err).ToNot(HaveOccurred())
+				return errors.New("broken")
+			}
+			return served(ctx, srv)
+		})
+		_, err := client.Send(ctx, target.Address, 1)
+		Expect(err).To(MatchError("broken"))
+		res, err := client.Send(ctx, target.Address, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(2))
+		Expect(net.StreamHistory()).To(HaveLen(2))
+	})
+	It("Should not block requests to other targets while opening a stream", func() {
+		server.Handle(func(ctx context.Context, req int) (int, error) { return req, nil })
+		client.Transport = blockingDialer{streamTransport: client.Transport, target: "unreachable"}
+		client.Timeout = 20 * time.Millisecond
+		errs := make(chan error)
+		go func() {
+			_, err := client.Send(ctx, "unreachable", 1)
+			errs <- err
+		}()
+		Consistently(errs, 5*time.Millisecond).ShouldNot(Receive())
+		res, err := client.Send(context.Background(), target.Address, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(2))
+		Eventually(errs).Should(Receive(MatchError(context.DeadlineExceeded)))
+	})
+})
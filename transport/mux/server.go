@@ -0,0 +1,80 @@
+package mux
+
+import (
+	"context"
+	"github.com/arya-analytics/x/transport"
+	"github.com/cockroachdb/errors"
+	"sync"
+)
+
+// serve handles the requests received over a stream, running the handler for each
+// request in its own goroutine. When the client closes the stream, serve waits for
+// in-flight requests to complete before returning.
+func serve[I, O transport.Message](
+	ctx context.Context,
+	srv transport.StreamServer[Request[I], Response[O]],
+	handler func(context.Context, I) (O, error),
+) error {
+	var (
+		wg     sync.WaitGroup
+		sendMu sync.Mutex
+		mu     sync.Mutex
+		// cancels holds the cancel functions of in-flight requests.
+		cancels = make(map[uint64]context.CancelFunc)
+	)
+	cancel := func(id uint64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if c, ok := cancels[id]; ok {
+			c()
+			delete(cancels, id)
+		}
+	}
+	for {
+		req, err := srv.Receive()
+		if err != nil {
+			if !errors.Is(err, transport.EOF) {
+				mu.Lock()
+				for _, c := range cancels {
+					c()
+				}
+				mu.Unlock()
+			}
+			wg.Wait()
+			if errors.Is(err, transport.EOF) {
+				return nil
+			}
+			return err
+		}
+		if req.Cancel {
+			cancel(req.ID)
+			continue
+		}
+		var (
+			rCtx    context.Context
+			rCancel context.CancelFunc
+		)
+		if req.Timeout > 0 {
+			rCtx, rCancel = context.WithTimeout(ctx, req.Timeout)
+		} else {
+			rCtx, rCancel = context.WithCancel(ctx)
+		}
+		mu.Lock()
+		cancels[req.ID] = rCancel
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel(req.ID)
+			payload, hErr := handler(rCtx, req.Payload)
+			res := Response[O]{ID: req.ID, Payload: payload}
+			if hErr != nil {
+				res.Error = hErr.Error()
+			}
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			// If the stream has broken, the client fails the request.
+			_ = srv.Send(res)
+		}()
+	}
+}
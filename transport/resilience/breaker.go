@@ -0,0 +1,171 @@
+package resilience
+
+import (
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/observe"
+	"github.com/cockroachdb/errors"
+	"sync"
+	"time"
+)
+
+// BreakerOpen is returned when a request is rejected because the circuit breaker for
+// its target is open.
+var BreakerOpen = errors.New("[transport.resilience] - circuit breaker open")
+
+// BreakerState is the state of the circuit breaker for a single address.
+type BreakerState uint8
+
+const (
+	// StateClosed is the default state. Requests are sent to the target.
+	StateClosed BreakerState = iota
+	// StateOpen is entered after too many consecutive failures. Requests are
+	// rejected until BreakerConfig.ResetTimeout has passed.
+	StateOpen
+	// StateHalfOpen is entered once the ResetTimeout has passed. A single probe
+	// request is allowed through. If it succeeds, the breaker closes. Otherwise, it
+	// opens again.
+	StateHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// BreakerConfig configures a Breaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures after which the breaker
+	// for an address opens. Defaults to 5.
+	FailureThreshold int
+	// ResetTimeout is the amount of time the breaker stays open before allowing a
+	// probe request. Defaults to 1 second.
+	ResetTimeout time.Duration
+	// Classify returns true if an error returned by a request means the target is
+	// unavailable, and should count as a failure. Other errors (i.e. application
+	// errors returned by the target's handler) count as successful requests, since
+	// the target responded. Defaults to IsTransient.
+	Classify func(err error) bool
+}
+
+const (
+	defaultFailureThreshold = 5
+	defaultResetTimeout     = time.Second
+)
+
+// BreakerChange is the value notified to observers of a Breaker when the state of
+// an address changes.
+type BreakerChange struct {
+	Target address.Address
+	State  BreakerState
+}
+
+// Breaker is a per-address circuit breaker. The state of each address can be
+// observed using OnChange. To create a new Breaker, call NewBreaker.
+type Breaker struct {
+	BreakerConfig
+	observe.Observer[BreakerChange]
+	mu      sync.Mutex
+	targets map[address.Address]*breakerTarget
+}
+
+type breakerTarget struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker creates a new Breaker using the provided configuration.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.ResetTimeout <= 0 {
+		cfg.ResetTimeout = defaultResetTimeout
+	}
+	if cfg.Classify == nil {
+		cfg.Classify = IsTransient
+	}
+	return &Breaker{
+		BreakerConfig: cfg,
+		Observer:      observe.New[BreakerChange](),
+		targets:       make(map[address.Address]*breakerTarget),
+	}
+}
+
+// State returns the current state of the breaker for the target.
+func (b *Breaker) State(target address.Address) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok := b.targets[target]; ok {
+		return t.state
+	}
+	return StateClosed
+}
+
+// Allow returns BreakerOpen if a request to the target should be rejected. If Allow
+// returns nil, the outcome of the request must be reported using Record or Cancel.
+func (b *Breaker) Allow(target address.Address) error {
+	b.mu.Lock()
+	t := b.target(target)
+	var changed bool
+	if t.state == StateOpen && time.Since(t.openedAt) >= b.ResetTimeout {
+		t.state, changed = StateHalfOpen, true
+	}
+	var err error
+	switch {
+	case t.state == StateOpen, t.state == StateHalfOpen && t.probing:
+		err = errors.Wrapf(BreakerOpen, "target %s", target)
+	case t.state == StateHalfOpen:
+		t.probing = true
+	}
+	b.mu.Unlock()
+	if changed {
+		b.Notify(BreakerChange{Target: target, State: StateHalfOpen})
+	}
+	return err
+}
+
+// Record records the outcome of a request to the target that was allowed by Allow.
+func (b *Breaker) Record(target address.Address, success bool) {
+	b.mu.Lock()
+	t := b.target(target)
+	prev := t.state
+	t.probing = false
+	if success {
+		t.state, t.failures = StateClosed, 0
+	} else {
+		t.failures++
+		if t.state == StateHalfOpen || t.failures >= b.FailureThreshold {
+			t.state, t.openedAt = StateOpen, time.Now()
+		}
+	}
+	state := t.state
+	b.mu.Unlock()
+	if state != prev {
+		b.Notify(BreakerChange{Target: target, State: state})
+	}
+}
+
+// Cancel reports that a request to the target that was allowed by Allow was cancelled
+// before it completed. Cancel doesn't affect the state of the breaker.
+func (b *Breaker) Cancel(target address.Address) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.target(target).probing = false
+}
+
+func (b *Breaker) target(addr address.Address) *breakerTarget {
+	t, ok := b.targets[addr]
+	if !ok {
+		t = &breakerTarget{}
+		b.targets[addr] = t
+	}
+	return t
+}
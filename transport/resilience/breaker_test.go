@@ -0,0 +1,69 @@
+package resilience_test
+
+import (
+	"github.com/arya-analytics/x/transport/resilience"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Breaker", func() {
+	var (
+		b       *resilience.Breaker
+		changes []resilience.BreakerChange
+	)
+	BeforeEach(func() {
+		changes = nil
+		b = resilience.NewBreaker(resilience.BreakerConfig{
+			FailureThreshold: 2,
+			ResetTimeout:     10 * time.Millisecond,
+		})
+		b.OnChange(func(c resilience.BreakerChange) { changes = append(changes, c) })
+	})
+	fail := func() {
+		Expect(b.Allow("localhost:0")).To(Succeed())
+		b.Record("localhost:0", false)
+	}
+	It("Should open after consecutive failures", func() {
+		fail()
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateClosed))
+		fail()
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateOpen))
+		Expect(errors.Is(b.Allow("localhost:0"), resilience.BreakerOpen)).To(BeTrue())
+		Expect(b.State("localhost:1")).To(Equal(resilience.StateClosed))
+		Expect(b.Allow("localhost:1")).To(Succeed())
+		Expect(changes).To(Equal([]resilience.BreakerChange{
+			{Target: "localhost:0", State: resilience.StateOpen},
+		}))
+	})
+	It("Should reset the failure count after a success", func() {
+		fail()
+		Expect(b.Allow("localhost:0")).To(Succeed())
+		b.Record("localhost:0", true)
+		fail()
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateClosed))
+	})
+	It("Should allow a single probe once the reset timeout passes", func() {
+		fail()
+		fail()
+		time.Sleep(15 * time.Millisecond)
+		Expect(b.Allow("localhost:0")).To(Succeed())
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateHalfOpen))
+		Expect(errors.Is(b.Allow("localhost:0"), resilience.BreakerOpen)).To(BeTrue())
+		b.Record("localhost:0", true)
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateClosed))
+		Expect(changes).To(Equal([]resilience.BreakerChange{
+			{Target: "localhost:0", State: resilience.StateOpen},
+			{Target: "localhost:0", State: resilience.StateHalfOpen},
+			{Target: "localhost:0", State: resilience.StateClosed},
+		}))
+	})
+	It("Should re-open if the probe fails", func() {
+		fail()
+		fail()
+		time.Sleep(15 * time.Millisecond)
+		fail()
+		Expect(b.State("localhost:0")).To(Equal(resilience.StateOpen))
+	})
+})
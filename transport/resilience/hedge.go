@@ -0,0 +1,89 @@
+package resilience
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/cockroachdb/errors"
+	"time"
+)
+
+// HedgeConfig configures the hedging of requests to multiple targets. A hedged
+// request is first sent to its target. If no response is received within Delay, the
+// request is also sent to the next target returned by Targets, and so on. The first
+// successful response is returned, and the remaining requests are cancelled.
+//
+// Hedging reduces tail latency at the cost of extra load, and should only be used
+// for idempotent requests.
+type HedgeConfig struct {
+	// Delay is the time to wait for a response before sending the request to the
+	// next target.
+	Delay time.Duration
+	// Targets returns the additional targets to send a request to the provided target
+	// to, in the order they should be tried.
+	Targets func(target address.Address) []address.Address
+}
+
+type hedgeResult[O any] struct {
+	res O
+	err error
+}
+
+// hedge sends the request to the target and its hedge targets using send. If every
+// request fails, the error of the last request to fail is returned, with the errors
+// of the others attached as secondary errors.
+func hedge[O any](
+	ctx context.Context,
+	cfg HedgeConfig,
+	target address.Address,
+	send func(ctx context.Context, target address.Address) (O, error),
+) (res O, err error) {
+	targets := append([]address.Address{target}, cfg.Targets(target)...)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		results        = make(chan hedgeResult[O], len(targets))
+		sent, received int
+		t              = time.NewTimer(cfg.Delay)
+	)
+	defer t.Stop()
+	launch := func() {
+		target := targets[sent]
+		sent++
+		go func() {
+			r, err := send(ctx, target)
+			results <- hedgeResult[O]{res: r, err: err}
+		}()
+		if !t.Stop() {
+			select {
+			case <-t.C:
+			default:
+			}
+		}
+		t.Reset(cfg.Delay)
+	}
+	launch()
+	for received < sent {
+		var next <-chan time.Time
+		if sent < len(targets) {
+			next = t.C
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case r := <-results:
+			received++
+			if r.err == nil {
+				return r.res, nil
+			}
+			err = errors.CombineErrors(r.err, err)
+			// If every request sent so far has failed, there's no reason to wait
+			// before trying the next target.
+			if received == sent && sent < len(targets) {
+				launch()
+			}
+		case <-next:
+			launch()
+		}
+	}
+	return res, err
+}
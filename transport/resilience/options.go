@@ -0,0 +1,36 @@
+package resilience
+
+type options struct {
+	retry   *RetryConfig
+	hedge   *HedgeConfig
+	breaker *Breaker
+}
+
+type Option func(o *options)
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRetry retries failed requests using the provided configuration.
+func WithRetry(cfg RetryConfig) Option {
+	return func(o *options) {
+		cfg = cfg.withDefaults()
+		o.retry = &cfg
+	}
+}
+
+// WithHedge hedges requests using the provided configuration.
+func WithHedge(cfg HedgeConfig) Option {
+	return func(o *options) { o.hedge = &cfg }
+}
+
+// WithBreaker rejects requests to targets whose circuit breaker is open. The same
+// Breaker can be shared between multiple clients.
+func WithBreaker(b *Breaker) Option {
+	return func(o *options) { o.breaker = b }
+}
@@ -0,0 +1,100 @@
+// Package resilience implements retries, hedging, and circuit breaking for
+// transport.UnaryClient. The policies are applied by a Policy, which can be used to
+// wrap a client directly using Wrap, or as part of a middleware chain using
+// Policy.Middleware.
+//
+// When multiple policies are enabled, each retry sends a (possibly hedged) request,
+// and every request to a single target is guarded by the target's circuit breaker.
+package resilience
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport"
+	"github.com/arya-analytics/x/transport/middleware"
+)
+
+// Policy applies the retry, hedging, and circuit breaking policies configured by its
+// options to unary requests. To create a new Policy, call NewPolicy.
+type Policy[I, O transport.Message] struct {
+	*options
+}
+
+// NewPolicy creates a new Policy using the provided options. A Policy with no options
+// sends requests unchanged.
+func NewPolicy[I, O transport.Message](opts ...Option) *Policy[I, O] {
+	return &Policy[I, O]{options: newOptions(opts...)}
+}
+
+// Send sends the request to the target using next, applying the Policy. Send
+// satisfies the middleware.UnaryClientInterceptor signature.
+func (p *Policy[I, O]) Send(
+	ctx context.Context,
+	target address.Address,
+	req I,
+	next middleware.UnaryClientHandler[I, O],
+) (res O, err error) {
+	send := func(ctx context.Context, target address.Address) (O, error) {
+		return p.guard(ctx, target, req, next)
+	}
+	attempt := func() error {
+		if p.hedge != nil && p.hedge.Targets != nil {
+			res, err = hedge(ctx, *p.hedge, target, send)
+		} else {
+			res, err = send(ctx, target)
+		}
+		return err
+	}
+	if p.retry == nil {
+		return res, attempt()
+	}
+	return res, p.retry.retry(ctx, attempt)
+}
+
+// guard sends the request to the target if its circuit breaker allows it.
+func (p *Policy[I, O]) guard(
+	ctx context.Context,
+	target address.Address,
+	req I,
+	next middleware.UnaryClientHandler[I, O],
+) (res O, err error) {
+	if p.breaker == nil {
+		return next(ctx, target, req)
+	}
+	if err = p.breaker.Allow(target); err != nil {
+		return res, err
+	}
+	res, err = next(ctx, target, req)
+	// Requests cancelled by the caller (or by hedging) say nothing about the health
+	// of the target.
+	if err != nil && ctx.Err() != nil {
+		p.breaker.Cancel(target)
+	} else {
+		p.breaker.Record(target, err == nil || !p.breaker.Classify(err))
+	}
+	return res, err
+}
+
+// Middleware returns Middleware that applies the Policy to unary client requests.
+func (p *Policy[I, O]) Middleware() middleware.Middleware[I, O] {
+	return middleware.Middleware[I, O]{UnaryClient: p.Send}
+}
+
+// Wrap returns a transport.UnaryClient that applies a Policy created using the
+// provided options to requests sent using the client.
+func Wrap[I, O transport.Message](
+	client transport.UnaryClient[I, O],
+	opts ...Option,
+) transport.UnaryClient[I, O] {
+	return &policyClient[I, O]{client: client, policy: NewPolicy[I, O](opts...)}
+}
+
+type policyClient[I, O transport.Message] struct {
+	client transport.UnaryClient[I, O]
+	policy *Policy[I, O]
+}
+
+// Send implements transport.UnaryClient.
+func (c *policyClient[I, O]) Send(ctx context.Context, target address.Address, req I) (O, error) {
+	return c.policy.Send(ctx, target, req, c.client.Send)
+}
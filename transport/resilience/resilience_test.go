@@ -0,0 +1,161 @@
+package resilience_test
+
+import (
+	"context"
+	"github.com/arya-analytics/x/address"
+	"github.com/arya-analytics/x/transport/middleware"
+	tmock "github.com/arya-analytics/x/transport/mock"
+	"github.com/arya-analytics/x/transport/resilience"
+	"github.com/cockroachdb/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync/atomic"
+	"time"
+)
+
+var _ = Describe("Policy", func() {
+	var (
+		net    *tmock.Network[int, int]
+		client *tmock.Unary[int, int]
+		// failures is the number of requests the server at localhost:1 fails before
+		// succeeding.
+		failures int32
+		calls    int32
+	)
+	BeforeEach(func() {
+		failures, calls = 0, 0
+		net = tmock.NewNetwork[int, int]()
+		client = net.RouteUnary("localhost:0")
+		net.RouteUnary("localhost:1").Handle(func(ctx context.Context, req int) (int, error) {
+			atomic.AddInt32(&calls, 1)
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				return 0, resilience.MarkTransient(errors.New("unavailable"))
+			}
+			return req + 1, nil
+		})
+		net.RouteUnary("localhost:2").Handle(func(ctx context.Context, req int) (int, error) {
+			return req + 2, nil
+		})
+	})
+	Describe("Retry", func() {
+		It("Should retry transient errors", func() {
+			failures = 2
+			c := resilience.Wrap[int, int](client, resilience.WithRetry(resilience.RetryConfig{
+				MaxRetries: 3,
+				Interval:   time.Millisecond,
+				Scale:      2,
+				Jitter:     0.5,
+			}))
+			res, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+		})
+		It("Should return the last error once retries are exhausted", func() {
+			failures = 5
+			c := resilience.Wrap[int, int](client, resilience.WithRetry(resilience.RetryConfig{
+				MaxRetries: 2,
+				Interval:   time.Millisecond,
+			}))
+			_, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(errors.Is(err, resilience.Transient)).To(BeTrue())
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+		})
+		It("Should not retry errors that aren't retryable", func() {
+			c := resilience.Wrap[int, int](client, resilience.WithRetry(resilience.RetryConfig{
+				MaxRetries: 2,
+				Interval:   time.Millisecond,
+			}))
+			_, err := c.Send(context.Background(), "localhost:3", 1)
+			Expect(errors.Is(err, address.NotFound)).To(BeTrue())
+		})
+	})
+	Describe("Hedge", func() {
+		var hedged resilience.Option
+		BeforeEach(func() {
+			hedged = resilience.WithHedge(resilience.HedgeConfig{
+				Delay: 5 * time.Millisecond,
+				Targets: func(address.Address) []address.Address {
+					return []address.Address{"localhost:2"}
+				},
+			})
+		})
+		It("Should return the response of the hedged target if the first is slow", func() {
+			net.SetLink("localhost:0", "localhost:1", tmock.LinkConfig{Latency: time.Second})
+			c := resilience.Wrap[int, int](client, hedged)
+			start := time.Now()
+			res, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(3))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+		It("Should not send to the hedged target if the first responds in time", func() {
+			c := resilience.Wrap[int, int](client, hedged)
+			res, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+		})
+		It("Should try the next target immediately if the first fails", func() {
+			failures = 1
+			c := resilience.Wrap[int, int](client, resilience.WithHedge(resilience.HedgeConfig{
+				Delay: time.Second,
+				Targets: func(address.Address) []address.Address {
+					return []address.Address{"localhost:2"}
+				},
+			}))
+			start := time.Now()
+			res, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(3))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+		It("Should retry if the last hedged request fails with a retryable error", func() {
+			net.RouteUnary("localhost:3").Handle(func(ctx context.Context, req int) (int, error) {
+				return 0, errors.New("bad request")
+			})
+			c := resilience.Wrap[int, int](
+				client,
+				resilience.WithHedge(resilience.HedgeConfig{
+					Delay: time.Second,
+					Targets: func(address.Address) []address.Address {
+						return []address.Address{"localhost:1"}
+					},
+				}),
+				resilience.WithRetry(resilience.RetryConfig{MaxRetries: 1, Interval: time.Millisecond}),
+			)
+			failures = 1
+			res, err := c.Send(context.Background(), "localhost:3", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(2))
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+		})
+	})
+	Describe("Breaker", func() {
+		It("Should reject requests once the breaker opens", func() {
+			failures = 10
+			b := resilience.NewBreaker(resilience.BreakerConfig{FailureThreshold: 2})
+			p := resilience.NewPolicy[int, int](resilience.WithBreaker(b))
+			c := middleware.WrapUnary[int, int](client, p.Middleware())
+			for i := 0; i < 2; i++ {
+				_, err := c.Send(context.Background(), "localhost:1", 1)
+				Expect(errors.Is(err, resilience.Transient)).To(BeTrue())
+			}
+			_, err := c.Send(context.Background(), "localhost:1", 1)
+			Expect(errors.Is(err, resilience.BreakerOpen)).To(BeTrue())
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+			Expect(b.State("localhost:1")).To(Equal(resilience.StateOpen))
+		})
+		It("Should not count application errors as failures", func() {
+			net.RouteUnary("localhost:3").Handle(func(ctx context.Context, req int) (int, error) {
+				return 0, errors.New("bad request")
+			})
+			b := resilience.NewBreaker(resilience.BreakerConfig{FailureThreshold: 2})
+			c := resilience.Wrap[int, int](client, resilience.WithBreaker(b))
+			for i := 0; i < 3; i++ {
+				_, err := c.Send(context.Background(), "localhost:3", 1)
+				Expect(err).To(MatchError("bad request"))
+			}
+			Expect(b.State("localhost:3")).To(Equal(resilience.StateClosed))
+		})
+	})
+})
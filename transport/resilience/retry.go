@@ -0,0 +1,99 @@
+package resilience
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// Transient is a marker for errors that can be safely retried. To mark an error as
+// transient, use MarkTransient.
+var Transient = errors.New("[transport.resilience] - transient error")
+
+// MarkTransient marks the error as transient, so that it's retried by the default
+// RetryConfig.Retryable classifier.
+func MarkTransient(err error) error { return errors.Mark(err, Transient) }
+
+// IsTransient is the default error classifier. It returns true if the error was
+// marked using MarkTransient, or is a net.Error that timed out.
+func IsTransient(err error) bool {
+	if errors.Is(err, Transient) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// RetryConfig configures the retrying of failed requests with exponential backoff.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	MaxRetries int
+	// Interval is the time to wait before the first retry. Defaults to 50
+	// milliseconds.
+	Interval time.Duration
+	// Scale is the factor the interval is multiplied by after each retry. A Scale less
+	// than 1 is treated as 1.
+	Scale float64
+	// MaxInterval caps the interval between retries. A MaxInterval of zero means no
+	// cap.
+	MaxInterval time.Duration
+	// Jitter is the fraction (between 0 and 1) of the interval that's randomly added
+	// to or subtracted from it, which prevents clients from retrying in lockstep.
+	Jitter float64
+	// Retryable returns true if the error should be retried. Defaults to IsTransient.
+	// Context errors are never retried.
+	Retryable func(err error) bool
+}
+
+const defaultRetryInterval = 50 * time.Millisecond
+
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.Interval <= 0 {
+		r.Interval = defaultRetryInterval
+	}
+	if r.Scale < 1 {
+		r.Scale = 1
+	}
+	if r.Retryable == nil {
+		r.Retryable = IsTransient
+	}
+	return r
+}
+
+// backoff returns the interval to wait before the provided retry (starting at 0).
+func (r RetryConfig) backoff(retry int) time.Duration {
+	interval := float64(r.Interval)
+	for i := 0; i < retry; i++ {
+		interval *= r.Scale
+		if r.MaxInterval > 0 && interval > float64(r.MaxInterval) {
+			break
+		}
+	}
+	if r.MaxInterval > 0 && interval > float64(r.MaxInterval) {
+		interval = float64(r.MaxInterval)
+	}
+	if r.Jitter > 0 {
+		interval += interval * r.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(interval)
+}
+
+func (r RetryConfig) retry(ctx context.Context, f func() error) error {
+	err := f()
+	for i := 0; i < r.MaxRetries && err != nil; i++ {
+		if ctx.Err() != nil || !r.Retryable(err) {
+			return err
+		}
+		t := time.NewTimer(r.backoff(i))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		err = f()
+	}
+	return err
+}